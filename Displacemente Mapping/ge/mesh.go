@@ -0,0 +1,59 @@
+package ge
+
+import (
+	"github.com/StevenTarazona/glcore/gfx"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//Mesh is an indexed triangle list. Normals, UVs and Tangents, when present, hold one entry per
+//position. Upload copies it to the GPU and Draw draws it with a single call.
+type Mesh struct {
+	Positions []mgl32.Vec3
+	Normals   []mgl32.Vec3
+	UVs       []mgl32.Vec2
+	Indices   []uint32
+	// Tangents are filled by Terrain
+	Tangents []mgl32.Vec4
+
+	vertexArray *gfx.VertexArray
+}
+
+//NewMesh returns a mesh with the given triangle list.
+func NewMesh(positions []mgl32.Vec3, normals []mgl32.Vec3, uvs []mgl32.Vec2, indices []uint32) *Mesh {
+	return &Mesh{Positions: positions, Normals: normals, UVs: uvs, Indices: indices}
+}
+
+//Upload creates the vertex array of the mesh with MeshLayout, or TerrainLayout when it has
+//tangents, replacing the previous one if it was already uploaded.
+func (m *Mesh) Upload() error {
+	m.Delete()
+	layout, data := MeshLayout, gfx.VertexData{
+		"position": m.Positions,
+		"normal":   m.Normals,
+		"texCoord": m.UVs,
+	}
+	if len(m.Tangents) > 0 && len(m.Tangents) == len(m.Positions) {
+		layout, data["tangent"] = TerrainLayout, m.Tangents
+	}
+	vertexArray, err := gfx.NewVertexArray(layout, data, m.Indices)
+	if err != nil {
+		return err
+	}
+	m.vertexArray = vertexArray
+	return nil
+}
+
+//Draw draws the uploaded mesh as triangles. The caller binds the program, textures and uniforms.
+func (m *Mesh) Draw() {
+	m.vertexArray.Draw(gl.TRIANGLES)
+}
+
+//Delete releases the vertex array of the mesh, the vertex data is kept so it can be uploaded
+//again.
+func (m *Mesh) Delete() {
+	if m.vertexArray != nil {
+		m.vertexArray.Delete()
+		m.vertexArray = nil
+	}
+}
@@ -2,6 +2,8 @@ package ge
 
 import (
 	"image"
+
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 //GetSquare ...
@@ -28,6 +30,27 @@ func GetSquareTriangles(hTiles int, vTiles int, tileLengths float32) (vertices [
 	return
 }
 
+//GetSquarePatches builds the GetSquare plane as quad patches for tessellation shaders, drawn
+//with DrawPatches(4). Every patch lists its corners h, v then h+1, v then h+1, v+1 then h, v+1,
+//so gl_TessCoord.x runs along x and gl_TessCoord.y along z, and quads tessellated with cw
+//winding face up.
+func GetSquarePatches(hTiles int, vTiles int, tileLengths float32) (vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	vOfset := (float32(vTiles) * tileLengths) / 2
+	hOfset := (float32(hTiles) * tileLengths) / 2
+	for v := 0; v <= vTiles; v++ {
+		for h := 0; h <= hTiles; h++ {
+			vertices = append(vertices, mgl32.Vec3{(float32(h) * tileLengths) - hOfset, 0, (float32(v) * tileLengths) - vOfset})
+			tCoords = append(tCoords, mgl32.Vec2{float32(h) / float32(hTiles), float32(v) / float32(vTiles)})
+			if h < hTiles && v < vTiles {
+				first := uint32(h + (hTiles+1)*v)
+				next := first + uint32(hTiles+1)
+				indices = append(indices, first, first+1, next+1, next)
+			}
+		}
+	}
+	return
+}
+
 func GetSquareStrip(hTiles int, vTiles int, tileLength float32) ([]float32, []float32, []uint32) {
 	vertices := make([]float32, 0, (hTiles+1)*(vTiles+1)*3)
 	tCoords := make([]float32, 0, (hTiles+1)*(vTiles+1)*3)
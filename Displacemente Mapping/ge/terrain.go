@@ -0,0 +1,164 @@
+package ge
+
+import (
+	"image"
+
+	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/gfx"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//TerrainLayout is MeshLayout with the terrain tangents at attribute location 3, shaders that
+//don't use them can ignore it.
+var TerrainLayout = gfx.VertexLayout{
+	Attributes: append(append([]gfx.VertexAttribute{}, MeshLayout.Attributes...),
+		gfx.VertexAttribute{Name: "tangent", Location: 3, Size: 4, Type: gl.FLOAT}),
+}
+
+//Terrain is the displaced grid of GetSquareStripDisplaced with smooth normals and tangents,
+//centered on the origin with hTiles x vTiles tiles along x and z. Height and Normal sample the
+//surface anywhere on it, to place objects or walk cameras on the ground. The grid is a Mesh
+//with one vertex per grid corner, row by row, and two triangles per tile facing up; it is
+//uploaded with TerrainLayout as it has tangents. They point along u, w is the sign of the
+//bitangent so that bitangent = w * cross(normal, tangent) points along v.
+type Terrain struct {
+	*Mesh
+	HTiles, VTiles int
+	TileLength     float32
+}
+
+//NewTerrain displaces the grid by the luminance of img, sampled bilinearly, so white is
+//magnitud high. Images are stretched over the whole terrain like in GetSquareStripDisplaced.
+func NewTerrain(hTiles int, vTiles int, tileLength float32, img image.Image, magnitud float32) *Terrain {
+	return NewTerrainFunc(hTiles, vTiles, tileLength, GetImageHeight(img, magnitud))
+}
+
+//NewTerrainFunc displaces the grid by height, called with the texture coordinates of every
+//vertex, from 0 to 1 across the terrain.
+func NewTerrainFunc(hTiles int, vTiles int, tileLength float32, height func(u, v float32) float32) *Terrain {
+	t := &Terrain{Mesh: NewMesh(nil, nil, nil, nil), HTiles: hTiles, VTiles: vTiles, TileLength: tileLength}
+	vOfset := (float32(vTiles) * tileLength) / 2
+	hOfset := (float32(hTiles) * tileLength) / 2
+	for v := 0; v <= vTiles; v++ {
+		for h := 0; h <= hTiles; h++ {
+			tCoordH, tCoordV := float32(h)/float32(hTiles), float32(v)/float32(vTiles)
+			t.Positions = append(t.Positions, mgl32.Vec3{(float32(h) * tileLength) - hOfset, height(tCoordH, tCoordV), (float32(v) * tileLength) - vOfset})
+			t.UVs = append(t.UVs, mgl32.Vec2{tCoordH, tCoordV})
+		}
+	}
+	t.Indices = getGridTriangles(hTiles, vTiles)
+
+	// central differences, one sided on the borders
+	t.Normals = make([]mgl32.Vec3, len(t.Positions))
+	t.Tangents = make([]mgl32.Vec4, len(t.Positions))
+	for v := 0; v <= vTiles; v++ {
+		for h := 0; h <= hTiles; h++ {
+			left, right := t.vertex(maxInt(h-1, 0), v), t.vertex(minInt(h+1, hTiles), v)
+			back, front := t.vertex(h, maxInt(v-1, 0)), t.vertex(h, minInt(v+1, vTiles))
+			dx := (right.Y() - left.Y()) / (right.X() - left.X())
+			dz := (front.Y() - back.Y()) / (front.Z() - back.Z())
+			i := h + (hTiles+1)*v
+			t.Normals[i] = mgl32.Vec3{-dx, 1, -dz}.Normalize()
+			tangent := mgl32.Vec3{1, dx, 0}.Normalize()
+			t.Tangents[i] = mgl32.Vec4{tangent.X(), tangent.Y(), tangent.Z(), -1}
+		}
+	}
+	return t
+}
+
+//GetImageHeight returns the luminance of img at texture coordinates u, v, interpolated
+//between the four nearest pixels, from 0 for black to magnitud for white.
+func GetImageHeight(img image.Image, magnitud float32) func(u, v float32) float32 {
+	bounds := img.Bounds()
+	lum := func(x, y int) float32 {
+		x = minInt(maxInt(x, 0), bounds.Dx()-1)
+		y = minInt(maxInt(y, 0), bounds.Dy()-1)
+		r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+		return float32(19595*r+38470*g+7471*b) / float32(65535<<16)
+	}
+	return func(u, v float32) float32 {
+		x := math32.Max(0, math32.Min(1, u)) * float32(bounds.Dx()-1)
+		y := math32.Max(0, math32.Min(1, v)) * float32(bounds.Dy()-1)
+		x0, y0 := int(x), int(y)
+		fx, fy := x-float32(x0), y-float32(y0)
+		top := lum(x0, y0)*(1-fx) + lum(x0+1, y0)*fx
+		bottom := lum(x0, y0+1)*(1-fx) + lum(x0+1, y0+1)*fx
+		return magnitud * (top*(1-fy) + bottom*fy)
+	}
+}
+
+//Size returns the extent of the terrain along x and z.
+func (t *Terrain) Size() (width, depth float32) {
+	return float32(t.HTiles) * t.TileLength, float32(t.VTiles) * t.TileLength
+}
+
+//Contains tells if x, z is over the terrain.
+func (t *Terrain) Contains(x, z float32) bool {
+	width, depth := t.Size()
+	return math32.Abs(x) <= width/2 && math32.Abs(z) <= depth/2
+}
+
+//Height returns the height of the surface at x, z, on the same triangles that are drawn.
+//Points outside the terrain get the height of the nearest border.
+func (t *Terrain) Height(x, z float32) float32 {
+	h, v, fx, fz := t.cell(x, z)
+	a, b, c, d := t.vertex(h, v).Y(), t.vertex(h+1, v).Y(), t.vertex(h, v+1).Y(), t.vertex(h+1, v+1).Y()
+	// the diagonal of every tile goes from h+1, v to h, v+1
+	if fx+fz <= 1 {
+		return a + (b-a)*fx + (c-a)*fz
+	}
+	return d + (c-d)*(1-fx) + (b-d)*(1-fz)
+}
+
+//Normal returns the smooth normal of the surface at x, z, interpolated from the normals of
+//the tile corners.
+func (t *Terrain) Normal(x, z float32) mgl32.Vec3 {
+	h, v, fx, fz := t.cell(x, z)
+	row := t.HTiles + 1
+	a, b := t.Normals[h+row*v], t.Normals[h+1+row*v]
+	c, d := t.Normals[h+row*(v+1)], t.Normals[h+1+row*(v+1)]
+	normal := a.Mul((1 - fx) * (1 - fz)).Add(b.Mul(fx * (1 - fz))).Add(c.Mul((1 - fx) * fz)).Add(d.Mul(fx * fz))
+	return normal.Normalize()
+}
+
+func (t *Terrain) vertex(h, v int) mgl32.Vec3 {
+	return t.Positions[h+(t.HTiles+1)*v]
+}
+
+//cell returns the tile under x, z and where x, z falls inside it, from 0 to 1.
+func (t *Terrain) cell(x, z float32) (h, v int, fx, fz float32) {
+	width, depth := t.Size()
+	x = math32.Max(0, math32.Min(width, x+width/2)) / t.TileLength
+	z = math32.Max(0, math32.Min(depth, z+depth/2)) / t.TileLength
+	h, v = minInt(int(x), t.HTiles-1), minInt(int(z), t.VTiles-1)
+	return h, v, x - float32(h), z - float32(v)
+}
+
+//getGridTriangles indexes the (hTiles+1) x (vTiles+1) vertices of the displaced grids, stored
+//row by row, as two triangles per tile wound like GetSquare. The strip indices of
+//GetSquareStripDisplaced can't be used as they are, every other row of the strip faces down.
+func getGridTriangles(hTiles int, vTiles int) (indices []uint32) {
+	for v := 0; v < vTiles; v++ {
+		for h := 0; h < hTiles; h++ {
+			first := uint32(h + (hTiles+1)*v)
+			next := first + uint32(hTiles+1)
+			indices = append(indices, first, next, first+1, next+1, first+1, next)
+		}
+	}
+	return
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -11,6 +11,18 @@ import (
 
 type Shader struct {
 	handle uint32
+	stage  uint32
+	// file the shader was loaded from, empty for NewShader
+	file string
+}
+
+//stageNames are the shader stages a 4.1 core context can compile.
+var stageNames = map[uint32]string{
+	gl.VERTEX_SHADER:          "VERTEX",
+	gl.TESS_CONTROL_SHADER:    "TESS_CONTROL",
+	gl.TESS_EVALUATION_SHADER: "TESS_EVALUATION",
+	gl.GEOMETRY_SHADER:        "GEOMETRY",
+	gl.FRAGMENT_SHADER:        "FRAGMENT",
 }
 
 type Program struct {
@@ -40,10 +52,38 @@ func (prog *Program) Use() {
 	gl.UseProgram(prog.handle)
 }
 
+//Link checks that the attached stages make a pipeline, a TESS_CONTROL stage
+//needs a TESS_EVALUATION one and every stage needs a VERTEX one, and links
+//them. Errors name the stages of the program.
 func (prog *Program) Link() error {
+	stages := map[uint32]bool{}
+	names := []string{}
+	for _, shader := range prog.shaders {
+		stages[shader.stage] = true
+		names = append(names, shader.String())
+	}
+	failMsg := fmt.Sprintf("PROGRAM::LINKING_FAILURE [%s]", strings.Join(names, ", "))
+	if !stages[gl.VERTEX_SHADER] {
+		return fmt.Errorf("%s: no VERTEX stage", failMsg)
+	}
+	if stages[gl.TESS_CONTROL_SHADER] && !stages[gl.TESS_EVALUATION_SHADER] {
+		return fmt.Errorf("%s: TESS_CONTROL stage without TESS_EVALUATION stage", failMsg)
+	}
+
 	gl.LinkProgram(prog.handle)
 	return getGlError(prog.handle, gl.LINK_STATUS, gl.GetProgramiv, gl.GetProgramInfoLog,
-		"PROGRAM::LINKING_FAILURE")
+		failMsg)
+}
+
+//Tessellated tells if the program has a TESS_EVALUATION stage, so it must
+//be drawn with patches, like VertexArray.DrawPatches.
+func (prog *Program) Tessellated() bool {
+	for _, shader := range prog.shaders {
+		if shader.stage == gl.TESS_EVALUATION_SHADER {
+			return true
+		}
+	}
+	return false
 }
 
 func (prog *Program) GetUniformLocation(name string) int32 {
@@ -61,37 +101,55 @@ func NewProgram(shaders ...*Shader) (*Program, error) {
 	return prog, nil
 }
 
+//NewShader compiles src as a stage of sType: gl.VERTEX_SHADER,
+//gl.TESS_CONTROL_SHADER, gl.TESS_EVALUATION_SHADER, gl.GEOMETRY_SHADER or
+//gl.FRAGMENT_SHADER. Errors name the stage.
 func NewShader(src string, sType uint32) (*Shader, error) {
-
-	handle := gl.CreateShader(sType)
-	glSrc, freeFn := gl.Strs(src + "\x00")
-	defer freeFn()
-	gl.ShaderSource(handle, 1, glSrc, nil)
-	gl.CompileShader(handle)
-	err := getGlError(handle, gl.COMPILE_STATUS, gl.GetShaderiv, gl.GetShaderInfoLog,
-		"SHADER::COMPILE_FAILURE::")
-	if err != nil {
-		return nil, err
-	}
-	return &Shader{handle: handle}, nil
+	return newShader(src, sType, "")
 }
 
+//NewShaderFromFile is NewShader with the source read from file, errors name
+//the stage and the file.
 func NewShaderFromFile(file string, sType uint32) (*Shader, error) {
 	src, err := ioutil.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
+	return newShader(string(src), sType, file)
+}
+
+func newShader(src string, sType uint32, file string) (*Shader, error) {
+	shader := &Shader{stage: sType, file: file}
+	if _, ok := stageNames[sType]; !ok {
+		return nil, fmt.Errorf("SHADER::UNKNOWN_STAGE::%s", shader)
+	}
+
 	handle := gl.CreateShader(sType)
-	glSrc, freeFn := gl.Strs(string(src) + "\x00")
+	glSrc, freeFn := gl.Strs(src + "\x00")
 	defer freeFn()
 	gl.ShaderSource(handle, 1, glSrc, nil)
 	gl.CompileShader(handle)
-	err = getGlError(handle, gl.COMPILE_STATUS, gl.GetShaderiv, gl.GetShaderInfoLog,
-		"SHADER::COMPILE_FAILURE::"+file)
+	err := getGlError(handle, gl.COMPILE_STATUS, gl.GetShaderiv, gl.GetShaderInfoLog,
+		"SHADER::COMPILE_FAILURE::"+shader.String())
 	if err != nil {
+		gl.DeleteShader(handle)
 		return nil, err
 	}
-	return &Shader{handle: handle}, nil
+	shader.handle = handle
+	return shader, nil
+}
+
+//String names the stage of the shader and its file, like
+//"FRAGMENT shaders/basic.frag".
+func (shader *Shader) String() string {
+	name, ok := stageNames[shader.stage]
+	if !ok {
+		name = fmt.Sprintf("0x%x", shader.stage)
+	}
+	if shader.file != "" {
+		name += " " + shader.file
+	}
+	return name
 }
 
 type getObjIv func(uint32, uint32, *int32)
@@ -1,14 +1,13 @@
 package main
 
 import (
-	"image"
+	"fmt"
 	"log"
-	"os"
 	"runtime"
-	"unsafe"
 
 	"github.com/StevenTarazona/glcore/ge"
 	"github.com/StevenTarazona/glcore/gfx"
+	"github.com/StevenTarazona/glcore/noise"
 	"github.com/StevenTarazona/glcore/win"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -20,22 +19,34 @@ const (
 	width  = 1080
 	height = 720
 	title  = "Core"
+
+	// displace the ground on the GPU with the tessellation shaders instead of building a
+	// ge.Terrain on the CPU
+	gpuDisplacement = true
 )
 
 func programLoop(window *win.Window) error {
 
 	// Shaders and textures
-	vertShader, err := gfx.NewShaderFromFile("shaders/basic.vert", gl.VERTEX_SHADER)
-	if err != nil {
-		return err
+	shaderFiles := map[uint32]string{
+		gl.VERTEX_SHADER:   "shaders/phong_ml.vert",
+		gl.FRAGMENT_SHADER: "shaders/phong_ml.frag",
 	}
-
-	fragShader, err := gfx.NewShaderFromFile("shaders/basic.frag", gl.FRAGMENT_SHADER)
-	if err != nil {
-		return err
+	if gpuDisplacement {
+		shaderFiles[gl.VERTEX_SHADER] = "shaders/displacement.vert"
+		shaderFiles[gl.TESS_CONTROL_SHADER] = "shaders/displacement.tesc"
+		shaderFiles[gl.TESS_EVALUATION_SHADER] = "shaders/displacement.tese"
+	}
+	shaders := []*gfx.Shader{}
+	for sType, file := range shaderFiles {
+		shader, err := gfx.NewShaderFromFile(file, sType)
+		if err != nil {
+			return err
+		}
+		shaders = append(shaders, shader)
 	}
 
-	program, err := gfx.NewProgram(vertShader, fragShader)
+	program, err := gfx.NewProgram(shaders...)
 	if err != nil {
 		return err
 	}
@@ -50,28 +61,28 @@ func programLoop(window *win.Window) error {
 	model := mgl32.Ident4()
 
 	// Uniform locations
-	WorldUniformLocation := program.GetUniformLocation("world")
+	WorldUniformLocation := program.GetUniformLocation("model")
 	colorUniformLocation := program.GetUniformLocation("objectColor")
-	lightColorUniformLocation := program.GetUniformLocation("lightColor")
-	cameraUniformLocation := program.GetUniformLocation("camera")
-	projectUniformLocation := program.GetUniformLocation("project")
-	textureUniformLocation := program.GetUniformLocation("texture")
+	cameraUniformLocation := program.GetUniformLocation("view")
+	projectUniformLocation := program.GetUniformLocation("projection")
+	textureUniformLocation := program.GetUniformLocation("texSampler0")
+	viewPosUniformLocation := program.GetUniformLocation("viewPos")
+	numLightsUniformLocation := program.GetUniformLocation("numLights")
 
 	// creates camara
 	//camera := mgl32.LookAtV(mgl32.Vec3{10, 5, -1}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
-	camera := mgl32.LookAtV(mgl32.Vec3{10, 2, 0}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	eye := mgl32.Vec3{10, 2, 0}
+	camera := mgl32.LookAtV(eye, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
 	gl.UniformMatrix4fv(cameraUniformLocation, 1, false, &camera[0])
+	gl.Uniform3fv(viewPosUniformLocation, 1, &eye[0])
 
 	// creates perspective
 	fov := float32(60.0)
 	projectTransform := mgl32.Perspective(mgl32.DegToRad(fov), float32(width)/height, 0.1, 100.0)
 	gl.UniformMatrix4fv(projectUniformLocation, 1, false, &projectTransform[0])
 
-	// creates light
-	gl.Uniform3f(lightColorUniformLocation, 1, 1, 1)
-
 	// Uncomment to turn on polygon mode
-	gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	//gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
 
 	// Scene and animation
 	angle := 0.0
@@ -90,21 +101,58 @@ func programLoop(window *win.Window) error {
 	}
 
 	// Get primitive vertices and create VAOs
-	imgFile, err := os.Open("images/noise2.jpg")
-	if err != nil {
-		panic(err.Error())
+	// ridged noise makes the snow drifts, 20 x 5 units of terrain over 8 x 2 cells of noise
+	ridged := noise.NewRidged(noise.NewSimplex(1), 6)
+	heights := noise.Heights(ridged, 0, 0, 8, 2, 5)
+	var drawGround func()
+	if gpuDisplacement {
+		// the same noise baked with square pixels, 8 cells across 1024 pixels
+		heightTexture, err := noise.Texture(ridged, 1024, 256, 8, gl.CLAMP_TO_EDGE, gl.CLAMP_TO_EDGE)
+		if err != nil {
+			return err
+		}
+		heightTexture.Bind(gl.TEXTURE1)
+		heightTexture.SetUniform(program.GetUniformLocation("heightMap"))
+		gl.Uniform1f(program.GetUniformLocation("magnitude"), 5)
+		gl.Uniform2f(program.GetUniformLocation("size"), 20, 5)
+		gl.Uniform1f(program.GetUniformLocation("minTessLevel"), 1)
+		gl.Uniform1f(program.GetUniformLocation("maxTessLevel"), 32)
+		gl.Uniform1f(program.GetUniformLocation("minDistance"), 2)
+		gl.Uniform1f(program.GetUniformLocation("maxDistance"), 25)
+
+		patchVertices, patchTCoords, patchIndices := ge.GetSquarePatches(20, 5, 1)
+		patches, err := gfx.NewVertexArray(ge.MeshLayout, gfx.VertexData{
+			"position": patchVertices,
+			"texCoord": patchTCoords,
+		}, patchIndices)
+		if err != nil {
+			return err
+		}
+		defer patches.Delete()
+		drawGround = func() { patches.DrawPatches(4) }
+	} else {
+		terrain := ge.NewTerrainFunc(200, 50, .1, heights)
+		if err := terrain.Upload(); err != nil {
+			return err
+		}
+		defer terrain.Delete()
+		drawGround = terrain.Draw
 	}
-	defer imgFile.Close()
 
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		panic(err.Error())
+	// creates lights, floating over the ground
+	pointLightPositions := []mgl32.Vec3{{-7, 0, -1.5}, {0, 0, 1.5}, {7, 0, -1.5}}
+	for i, position := range pointLightPositions {
+		position[1] = heights((position.X()+10)/20, (position.Z()+2.5)/5) + 2
+		gl.Uniform3fv(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].position")), 1, &position[0])
+		gl.Uniform3f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].ambient")), .1, .1, .1)
+		gl.Uniform3f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].diffuse")), 1, 1, 1)
+		gl.Uniform3f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].specular")), .3, .3, .3)
+		gl.Uniform1f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].constant")), 1.)
+		gl.Uniform1f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].linear")), 0.09)
+		gl.Uniform1f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].quadratic")), 0.032)
+		gl.Uniform3f(program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].lightColor")), 1, 1, 1)
 	}
-
-	squareVertices, squareTCoords, squareIndices := ge.GetSquareStripDisplaced(200, 50, .1, img, 5)
-	squareVAO := ge.CreateVAO(squareVertices, squareTCoords, squareIndices)
-	count := int32(len(squareIndices))
-	squareVertices, squareTCoords, squareIndices = nil, nil, nil
+	gl.Uniform1i(numLightsUniformLocation, int32(len(pointLightPositions)))
 
 	for !window.ShouldClose() {
 		window.StartFrame()
@@ -137,14 +185,10 @@ func programLoop(window *win.Window) error {
 		iceTexture.Bind(gl.TEXTURE0)
 		iceTexture.SetUniform(textureUniformLocation)
 
-		gl.BindVertexArray(squareVAO)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &model[0])
-		gl.DrawElements(gl.TRIANGLE_STRIP, count, gl.UNSIGNED_INT, unsafe.Pointer(nil))
-		//gl.DrawArrays(gl.TRIANGLE_STRIP, 0, count)
+		drawGround()
 
 		iceTexture.UnBind()
-
-		gl.BindVertexArray(0)
 	}
 
 	return nil
@@ -0,0 +1,72 @@
+package noise
+
+//FBM is fractional Brownian motion: octaves of a source added together,
+//each one Lacunarity times finer and Gain times weaker than the previous.
+type FBM struct {
+	Source     Source2
+	Octaves    int
+	Lacunarity float32
+	Gain       float32
+}
+
+//NewFBM sums octaves of source, doubling the frequency and halving the
+//amplitude every octave.
+func NewFBM(source Source2, octaves int) *FBM {
+	return &FBM{Source: source, Octaves: octaves, Lacunarity: 2, Gain: .5}
+}
+
+//Noise2 returns the sum at x, y, scaled back to [-1, 1].
+func (f *FBM) Noise2(x, y float32) float32 {
+	sum, amplitude, total := float32(0), float32(1), float32(0)
+	for octave := 0; octave < f.Octaves; octave++ {
+		sum += amplitude * f.Source.Noise2(x, y)
+		total += amplitude
+		x, y = x*f.Lacunarity, y*f.Lacunarity
+		amplitude *= f.Gain
+	}
+	if total == 0 {
+		return 0
+	}
+	return clamp(sum/total, -1, 1)
+}
+
+//Ridged is ridged multifractal noise: octaves of 1 - |source| make sharp
+//crests where the source crosses 0, and every octave is weighted by the
+//previous one so valleys stay smooth. It suits mountains better than FBM.
+type Ridged struct {
+	Source     Source2
+	Octaves    int
+	Lacunarity float32
+	Gain       float32
+}
+
+//NewRidged sums octaves of source like NewFBM.
+func NewRidged(source Source2, octaves int) *Ridged {
+	return &Ridged{Source: source, Octaves: octaves, Lacunarity: 2, Gain: .5}
+}
+
+//Noise2 returns the sum at x, y, -1 in the valleys and up to 1 on the
+//crests.
+func (r *Ridged) Noise2(x, y float32) float32 {
+	sum, amplitude, total, weight := float32(0), float32(1), float32(0), float32(1)
+	for octave := 0; octave < r.Octaves; octave++ {
+		signal := 1 - abs(r.Source.Noise2(x, y))
+		signal *= signal * weight
+		weight = clamp(signal*2, 0, 1)
+		sum += amplitude * signal
+		total += amplitude
+		x, y = x*r.Lacunarity, y*r.Lacunarity
+		amplitude *= r.Gain
+	}
+	if total == 0 {
+		return 0
+	}
+	return clamp(2*sum/total-1, -1, 1)
+}
+
+func abs(value float32) float32 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
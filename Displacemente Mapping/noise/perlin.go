@@ -0,0 +1,103 @@
+package noise
+
+//Perlin is Ken Perlin's improved gradient noise, one unit per lattice cell.
+type Perlin struct {
+	perm *permutation
+}
+
+//NewPerlin returns Perlin noise shuffled by seed.
+func NewPerlin(seed int64) *Perlin {
+	return &Perlin{perm: newPermutation(seed)}
+}
+
+//Noise2 returns the noise at x, y. It is 0 on every lattice point.
+func (p *Perlin) Noise2(x, y float32) float32 {
+	x0, y0 := fastFloor(x), fastFloor(y)
+	fx, fy := x-float32(x0), y-float32(y0)
+	u, v := fade(fx), fade(fy)
+
+	n00 := grad2(p.perm.hash2(x0, y0), fx, fy)
+	n10 := grad2(p.perm.hash2(x0+1, y0), fx-1, fy)
+	n01 := grad2(p.perm.hash2(x0, y0+1), fx, fy-1)
+	n11 := grad2(p.perm.hash2(x0+1, y0+1), fx-1, fy-1)
+	return clamp(lerp(lerp(n00, n10, u), lerp(n01, n11, u), v), -1, 1)
+}
+
+//Noise3 returns the noise at x, y, z. It is 0 on every lattice point.
+func (p *Perlin) Noise3(x, y, z float32) float32 {
+	x0, y0, z0 := fastFloor(x), fastFloor(y), fastFloor(z)
+	fx, fy, fz := x-float32(x0), y-float32(y0), z-float32(z0)
+	u, v, w := fade(fx), fade(fy), fade(fz)
+
+	n000 := grad3(p.perm.hash3(x0, y0, z0), fx, fy, fz)
+	n100 := grad3(p.perm.hash3(x0+1, y0, z0), fx-1, fy, fz)
+	n010 := grad3(p.perm.hash3(x0, y0+1, z0), fx, fy-1, fz)
+	n110 := grad3(p.perm.hash3(x0+1, y0+1, z0), fx-1, fy-1, fz)
+	n001 := grad3(p.perm.hash3(x0, y0, z0+1), fx, fy, fz-1)
+	n101 := grad3(p.perm.hash3(x0+1, y0, z0+1), fx-1, fy, fz-1)
+	n011 := grad3(p.perm.hash3(x0, y0+1, z0+1), fx, fy-1, fz-1)
+	n111 := grad3(p.perm.hash3(x0+1, y0+1, z0+1), fx-1, fy-1, fz-1)
+	return clamp(lerp(
+		lerp(lerp(n000, n100, u), lerp(n010, n110, u), v),
+		lerp(lerp(n001, n101, u), lerp(n011, n111, u), v),
+		w), -1, 1)
+}
+
+//fade is the quintic curve 6t^5 - 15t^4 + 10t^3.
+func fade(t float32) float32 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(a, b, t float32) float32 {
+	return a + t*(b-a)
+}
+
+//grad2 dots x, y with one of 8 gradients, the axes and the diagonals.
+func grad2(hash int, x, y float32) float32 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	}
+	return -y
+}
+
+//grad3 dots x, y, z with one of the 12 cube edge gradients.
+func grad3(hash int, x, y, z float32) float32 {
+	switch hash % 12 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x + z
+	case 5:
+		return -x + z
+	case 6:
+		return x - z
+	case 7:
+		return -x - z
+	case 8:
+		return y + z
+	case 9:
+		return -y + z
+	case 10:
+		return y - z
+	}
+	return -y - z
+}
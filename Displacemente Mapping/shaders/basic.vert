@@ -1,7 +1,7 @@
 #version 410 core
 
 layout (location = 0) in vec3 position;
-layout (location = 1) in vec2 texCoord;
+layout (location = 2) in vec2 texCoord;
 
 uniform mat4 world;
 uniform mat4 camera;
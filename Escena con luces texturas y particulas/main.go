@@ -8,6 +8,8 @@ import (
 	"unsafe"
 
 	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/ge"
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/kaitsubaka/glutils/gfx"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -43,75 +45,14 @@ var (
 	}
 )
 
-func createVAO(vertices, normals, tCoords []float32, indices []uint32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	var NBO uint32
-	gl.GenBuffers(1, &NBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, NBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(normals)*4, gl.Ptr(normals), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(1)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	if len(tCoords) > 0 {
-		var TBO uint32
-		gl.GenBuffers(1, &TBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, TBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(tCoords)*4, gl.Ptr(tCoords), gl.STATIC_DRAW)
-		gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 2*4, gl.PtrOffset(0))
-		gl.EnableVertexAttribArray(2)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	}
-
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	gl.BindVertexArray(0)
-
-	return VAO
-}
-
-func createParticleVAO(points []float32) (uint32, uint32) {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(points)*4, gl.Ptr(points), gl.STATIC_DRAW)
-
-	// size of one whole vertex (sum of attrib sizes)
-	var stride int32 = 3*4 + 4*4 + 4
-	var offset int = 0
-
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.EnableVertexAttribArray(1)
-	offset += 3 * 4
-	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.EnableVertexAttribArray(2)
-	offset += 4 * 4
-	gl.VertexAttribPointer(2, 1, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.BindVertexArray(0)
-
-	return VAO, VBO
+// newMeshVertexArray uploads the geometry of Sphere, Square... following ge.MeshLayout, the
+// attribute locations of the phong shaders
+func newMeshVertexArray(vertices, normals, tCoords []float32, indices []uint32) (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(ge.MeshLayout, glcore.VertexData{
+		"position": vertices,
+		"normal":   normals,
+		"texCoord": tCoords,
+	}, indices)
 }
 
 func pointLightsUL(program *gfx.Program) [][]int32 {
@@ -326,16 +267,44 @@ func programLoop(window *Window) error {
 	}
 
 	// Geometry
-	particle1VAO, particle1VBO := createParticleVAO(particles1.points)
-	particle2VAO, particle2VBO := createParticleVAO(particles2.points)
-	particle3VAO, particle3VBO := createParticleVAO(particles3.points)
+	particle1VAO, err := particles1.NewVertexArray()
+	if err != nil {
+		return err
+	}
+	defer particle1VAO.Delete()
+	particle2VAO, err := particles2.NewVertexArray()
+	if err != nil {
+		return err
+	}
+	defer particle2VAO.Delete()
+	particle3VAO, err := particles3.NewVertexArray()
+	if err != nil {
+		return err
+	}
+	defer particle3VAO.Delete()
 	xLightSegments, yLighteSegments := 30, 30
-	lightVAO := createVAO(Sphere(xLightSegments, yLighteSegments))
+	lightVAO, err := newMeshVertexArray(Sphere(xLightSegments, yLighteSegments))
+	if err != nil {
+		return err
+	}
+	defer lightVAO.Delete()
 	xPlaneSegments, yPlaneSegments := 15, 15
-	planeVAO := createVAO(Square(xPlaneSegments, yPlaneSegments, 1))
-	skyVAO := createVAO(Cube(50, 50, 50))
+	planeVAO, err := newMeshVertexArray(Square(xPlaneSegments, yPlaneSegments, 1))
+	if err != nil {
+		return err
+	}
+	defer planeVAO.Delete()
+	skyVAO, err := newMeshVertexArray(Cube(50, 50, 50))
+	if err != nil {
+		return err
+	}
+	defer skyVAO.Delete()
 	xTrunkSegments, yTrunkSegments, zTrunkSegments := 15, 15, 2
-	trunkVAO := createVAO(Cylinder(xTrunkSegments, yTrunkSegments, zTrunkSegments))
+	trunkVAO, err := newMeshVertexArray(Cylinder(xTrunkSegments, yTrunkSegments, zTrunkSegments))
+	if err != nil {
+		return err
+	}
+	defer trunkVAO.Delete()
 	treePos, treeAngles := treePos(-float32(yPlaneSegments)/2, float32(yPlaneSegments)/2, -float32(xPlaneSegments)/2, float32(xPlaneSegments)/2, 1.5)
 
 	var numColor int
@@ -437,7 +406,7 @@ func programLoop(window *Window) error {
 		// render models
 		//Trees
 
-		gl.BindVertexArray(trunkVAO)
+		trunkVAO.Bind()
 		woodTexture.Bind(gl.TEXTURE0)
 		woodTexture.SetUniform(texture0UL)
 		for i, pos := range treePos {
@@ -488,7 +457,7 @@ func programLoop(window *Window) error {
 		gl.BindVertexArray(0)
 
 		//Plane
-		gl.BindVertexArray(planeVAO)
+		planeVAO.Bind()
 		earthTexture.Bind(gl.TEXTURE0)
 		earthTexture.SetUniform(texture0UL)
 		pathTexture.Bind(gl.TEXTURE1)
@@ -505,7 +474,7 @@ func programLoop(window *Window) error {
 		gl.UniformMatrix4fv(sourceViewUL, 1, false, &camTransform[0])
 
 		//Sky box
-		gl.BindVertexArray(skyVAO)
+		skyVAO.Bind()
 		starsTexture.Bind(gl.TEXTURE0)
 		starsTexture.SetUniform(sourceTextureUL)
 		gl.Uniform3f(sourceObjectColorUL, backgroundColor.X(), backgroundColor.Y(), backgroundColor.Z())
@@ -515,7 +484,7 @@ func programLoop(window *Window) error {
 		gl.BindVertexArray(0)
 
 		//Light objects
-		gl.BindVertexArray(lightVAO)
+		lightVAO.Bind()
 		for i, lp := range lightPositions {
 			gl.Uniform3f(sourceObjectColorUL, lightColors[i].X(), lightColors[i].Y(), lightColors[i].Z())
 			lightTransform := model
@@ -543,23 +512,17 @@ func programLoop(window *Window) error {
 		gl.DepthMask(false)
 		gl.Enable(gl.BLEND)
 
-		gl.BindVertexArray(particle1VAO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, particle1VBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(particles1.points)*4, gl.Ptr(particles1.points), gl.STATIC_DRAW)
-		gl.DrawArrays(gl.POINTS, 0, int32(numParticles))
-		gl.BindVertexArray(0)
+		if err := particles1.Draw(particle1VAO); err != nil {
+			return err
+		}
 
-		gl.BindVertexArray(particle2VAO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, particle2VBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(particles2.points)*4, gl.Ptr(particles2.points), gl.STATIC_DRAW)
-		gl.DrawArrays(gl.POINTS, 0, int32(numParticles))
-		gl.BindVertexArray(0)
+		if err := particles2.Draw(particle2VAO); err != nil {
+			return err
+		}
 
-		gl.BindVertexArray(particle3VAO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, particle3VBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(particles3.points)*4, gl.Ptr(particles3.points), gl.STATIC_DRAW)
-		gl.DrawArrays(gl.POINTS, 0, int32(numParticles))
-		gl.BindVertexArray(0)
+		if err := particles3.Draw(particle3VAO); err != nil {
+			return err
+		}
 
 		gl.DepthMask(true)
 		gl.Disable(gl.BLEND)
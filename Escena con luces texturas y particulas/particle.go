@@ -3,12 +3,27 @@ package main
 import (
 	"math/rand"
 
+	glcore "github.com/StevenTarazona/glcore/gfx"
+	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
+// particleLayout is the input of shaders/particles.vert, every particle is an instance of a
+// single point that the geometry shader turns into a quad
+var particleLayout = glcore.VertexLayout{
+	Attributes: []glcore.VertexAttribute{
+		{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Divisor: 1},
+		{Name: "color", Location: 1, Size: 4, Type: gl.FLOAT, Divisor: 1},
+		{Name: "size", Location: 2, Size: 1, Type: gl.FLOAT, Divisor: 1},
+	},
+	Usage: gl.STREAM_DRAW,
+}
+
 type Particles struct {
 	particles                     []Particle
-	points                        []float32
+	positions                     []mgl32.Vec3
+	colors                        []mgl32.Vec4
+	sizes                         []float32
 	color                         mgl32.Vec3
 	position, velocity, amplitude mgl32.Vec3
 	minLife, maxLife, size        float32
@@ -21,27 +36,28 @@ type Particle struct {
 }
 
 func (p *Particles) addParticle(index int, life, size float32) {
-	pos := index * 8
-	p.points[pos], p.points[pos+1], p.points[pos+2] = p.position.X(), p.position.Y(), p.position.Z()
-	p.points[pos+3], p.points[pos+4], p.points[pos+5], p.points[pos+6] = p.color.X(), p.color.Y(), p.color.Z(), 1
-	p.points[pos+7] = size
+	p.positions[index] = p.position
+	p.colors[index] = p.color.Vec4(1)
+	p.sizes[index] = size
 
 	p.particles[index] = Particle{
-		x:        &p.points[pos],
-		y:        &p.points[pos+1],
-		z:        &p.points[pos+2],
+		x:        &p.positions[index][0],
+		y:        &p.positions[index][1],
+		z:        &p.positions[index][2],
 		life0:    life,
 		life:     0,
-		size:     &p.points[pos+7],
+		size:     &p.sizes[index],
 		velocity: p.velocity,
-		alpha:    &p.points[pos+6],
+		alpha:    &p.colors[index][3],
 	}
 }
 
 func NewParticles(numParticles int, color mgl32.Vec3, position, velocity, amplitude mgl32.Vec3, minLife, maxLife, size float32) *Particles {
 	particles := Particles{
 		particles: make([]Particle, numParticles),
-		points:    make([]float32, numParticles*8),
+		positions: make([]mgl32.Vec3, numParticles),
+		colors:    make([]mgl32.Vec4, numParticles),
+		sizes:     make([]float32, numParticles),
 		color:     color,
 		position:  position,
 		velocity:  velocity,
@@ -80,3 +96,23 @@ func (p *Particles) Update(dT float32, position, velocity mgl32.Vec3) {
 		}
 	}
 }
+
+func (p *Particles) vertexData() glcore.VertexData {
+	return glcore.VertexData{"position": p.positions, "color": p.colors, "size": p.sizes}
+}
+
+// NewVertexArray uploads the particles following particleLayout
+func (p *Particles) NewVertexArray() (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(particleLayout, p.vertexData(), nil)
+}
+
+// Draw uploads the particles to vertexArray, made by NewVertexArray, and draws them as points
+func (p *Particles) Draw(vertexArray *glcore.VertexArray) error {
+	for name, data := range p.vertexData() {
+		if err := vertexArray.Update(name, data); err != nil {
+			return err
+		}
+	}
+	vertexArray.DrawInstanced(gl.POINTS, int32(len(p.particles)))
+	return nil
+}
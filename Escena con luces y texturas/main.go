@@ -8,6 +8,8 @@ import (
 	"unsafe"
 
 	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/ge"
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/kaitsubaka/glutils/gfx"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -43,47 +45,14 @@ var (
 	}
 )
 
-func createVAO(vertices, normals, tCoords []float32, indices []uint32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	var NBO uint32
-	gl.GenBuffers(1, &NBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, NBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(normals)*4, gl.Ptr(normals), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(1)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	if len(tCoords) > 0 {
-		var TBO uint32
-		gl.GenBuffers(1, &TBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, TBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(tCoords)*4, gl.Ptr(tCoords), gl.STATIC_DRAW)
-		gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 2*4, gl.PtrOffset(0))
-		gl.EnableVertexAttribArray(2)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	}
-
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	gl.BindVertexArray(0)
-
-	return VAO
+// newMeshVertexArray uploads the geometry of Sphere, Square... following ge.MeshLayout, the
+// attribute locations of the phong shaders
+func newMeshVertexArray(vertices, normals, tCoords []float32, indices []uint32) (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(ge.MeshLayout, glcore.VertexData{
+		"position": vertices,
+		"normal":   normals,
+		"texCoord": tCoords,
+	}, indices)
 }
 
 func pointLightsUniformLocations(program *gfx.Program) [][]int32 {
@@ -248,12 +217,28 @@ func programLoop(window *Window) error {
 
 	// Geometry
 	xLightSegments, yLighteSegments := 30, 30
-	lightVAO := createVAO(Sphere(xLightSegments, yLighteSegments))
+	lightVAO, err := newMeshVertexArray(Sphere(xLightSegments, yLighteSegments))
+	if err != nil {
+		return err
+	}
+	defer lightVAO.Delete()
 	xPlaneSegments, yPlaneSegments := 15, 15
-	planeVAO := createVAO(Square(xPlaneSegments, yPlaneSegments, 1))
-	skyVAO := createVAO(Cube(50, 50, 50))
+	planeVAO, err := newMeshVertexArray(Square(xPlaneSegments, yPlaneSegments, 1))
+	if err != nil {
+		return err
+	}
+	defer planeVAO.Delete()
+	skyVAO, err := newMeshVertexArray(Cube(50, 50, 50))
+	if err != nil {
+		return err
+	}
+	defer skyVAO.Delete()
 	xTrunkSegments, yTrunkSegments, zTrunkSegments := 15, 15, 2
-	trunkVAO := createVAO(Cylinder(xTrunkSegments, yTrunkSegments, zTrunkSegments))
+	trunkVAO, err := newMeshVertexArray(Cylinder(xTrunkSegments, yTrunkSegments, zTrunkSegments))
+	if err != nil {
+		return err
+	}
+	defer trunkVAO.Delete()
 	treePos, treeAngles := treePos(-float32(yPlaneSegments)/2, float32(yPlaneSegments)/2, -float32(xPlaneSegments)/2, float32(xPlaneSegments)/2, 1.5)
 
 	var numColor int
@@ -354,7 +339,7 @@ func programLoop(window *Window) error {
 		// render models
 		//Trees
 
-		gl.BindVertexArray(trunkVAO)
+		trunkVAO.Bind()
 		woodTexture.Bind(gl.TEXTURE0)
 		woodTexture.SetUniform(texture0UniformLocation)
 		for i, pos := range treePos {
@@ -405,7 +390,7 @@ func programLoop(window *Window) error {
 		gl.BindVertexArray(0)
 
 		//Plane
-		gl.BindVertexArray(planeVAO)
+		planeVAO.Bind()
 		earthTexture.Bind(gl.TEXTURE0)
 		earthTexture.SetUniform(texture0UniformLocation)
 
@@ -424,7 +409,7 @@ func programLoop(window *Window) error {
 		gl.UniformMatrix4fv(viewSourceUniformLocation, 1, false, &camTransform[0])
 
 		//Sky box
-		gl.BindVertexArray(skyVAO)
+		skyVAO.Bind()
 		starsTexture.Bind(gl.TEXTURE0)
 		starsTexture.SetUniform(textureSourceUniformLocation)
 		gl.Uniform3f(objectColorSourceUniformLocation, backgroundColor.X(), backgroundColor.Y(), backgroundColor.Z())
@@ -434,7 +419,7 @@ func programLoop(window *Window) error {
 		gl.BindVertexArray(0)
 
 		//Light objects
-		gl.BindVertexArray(lightVAO)
+		lightVAO.Bind()
 		for i, lp := range pointLightPositions {
 			gl.Uniform3f(objectColorSourceUniformLocation, pointLightColors[i].X(), pointLightColors[i].Y(), pointLightColors[i].Z())
 			cubeM := mgl32.Ident4()
@@ -0,0 +1,214 @@
+package ge
+
+import (
+	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/gfx"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//Mesh is an indexed triangle list. Normals and UVs, when present, hold one entry per position.
+//Upload copies it to the GPU and Draw draws it with a single call.
+type Mesh struct {
+	Positions []mgl32.Vec3
+	Normals   []mgl32.Vec3
+	UVs       []mgl32.Vec2
+	Indices   []uint32
+
+	vertexArray *gfx.VertexArray
+}
+
+//NewMesh returns a mesh with the given triangle list.
+func NewMesh(positions []mgl32.Vec3, normals []mgl32.Vec3, uvs []mgl32.Vec2, indices []uint32) *Mesh {
+	return &Mesh{Positions: positions, Normals: normals, UVs: uvs, Indices: indices}
+}
+
+//Upload creates the vertex array of the mesh with MeshLayout, replacing the previous one if it
+//was already uploaded.
+func (m *Mesh) Upload() error {
+	m.Delete()
+	vertexArray, err := gfx.NewVertexArray(MeshLayout, gfx.VertexData{
+		"position": m.Positions,
+		"normal":   m.Normals,
+		"texCoord": m.UVs,
+	}, m.Indices)
+	if err != nil {
+		return err
+	}
+	m.vertexArray = vertexArray
+	return nil
+}
+
+//Draw draws the uploaded mesh as triangles. The caller binds the program, textures and uniforms.
+func (m *Mesh) Draw() {
+	m.vertexArray.Draw(gl.TRIANGLES)
+}
+
+//Delete releases the vertex array of the mesh, the vertex data is kept so it can be uploaded
+//again.
+func (m *Mesh) Delete() {
+	if m.vertexArray != nil {
+		m.vertexArray.Delete()
+		m.vertexArray = nil
+	}
+}
+
+//Append adds the triangles of other to the mesh. Attributes only one of them has are filled
+//with zeros on the other, so they stay one per position.
+func (m *Mesh) Append(other *Mesh) {
+	m.appendVertices(other.Positions, other.Normals, other.UVs)
+	offset := uint32(len(m.Positions) - len(other.Positions))
+	for _, index := range other.Indices {
+		m.Indices = append(m.Indices, index+offset)
+	}
+}
+
+//AppendStrip adds vertices drawn as a TRIANGLE_STRIP, the way the primitive sides are built.
+//Normals and uvs may be nil.
+func (m *Mesh) AppendStrip(vertices []mgl32.Vec3, normals []mgl32.Vec3, uvs []mgl32.Vec2) {
+	first := uint32(len(m.Positions))
+	m.appendVertices(vertices, normals, uvs)
+	for i := uint32(0); i+2 < uint32(len(vertices)); i++ {
+		m.appendTriangle(first+i, first+i+1, first+i+2)
+	}
+}
+
+//AppendFan adds vertices drawn as a TRIANGLE_FAN, the way the primitive caps are built.
+//Normals and uvs may be nil.
+func (m *Mesh) AppendFan(vertices []mgl32.Vec3, normals []mgl32.Vec3, uvs []mgl32.Vec2) {
+	first := uint32(len(m.Positions))
+	m.appendVertices(vertices, normals, uvs)
+	for i := uint32(1); i+1 < uint32(len(vertices)); i++ {
+		m.appendTriangle(first, first+i, first+i+1)
+	}
+}
+
+//appendVertices adds vertices with their attributes. The attributes missing on the mesh or on
+//the new vertices are filled with zeros, to keep them aligned with the positions.
+func (m *Mesh) appendVertices(vertices []mgl32.Vec3, normals []mgl32.Vec3, uvs []mgl32.Vec2) {
+	count := len(m.Positions)
+	m.Positions = append(m.Positions, vertices...)
+	if len(m.Normals) > 0 || len(normals) > 0 {
+		m.Normals = append(resizeVec3(m.Normals, count), resizeVec3(normals, len(vertices))...)
+	}
+	if len(m.UVs) > 0 || len(uvs) > 0 {
+		m.UVs = append(resizeVec2(m.UVs, count), resizeVec2(uvs, len(vertices))...)
+	}
+}
+
+//resizeVec3 cuts or pads with zeros values to length, the values given are never written.
+func resizeVec3(values []mgl32.Vec3, length int) []mgl32.Vec3 {
+	if len(values) >= length {
+		return values[:length]
+	}
+	return append(values[:len(values):len(values)], make([]mgl32.Vec3, length-len(values))...)
+}
+
+//resizeVec2 is resizeVec3 for uvs.
+func resizeVec2(values []mgl32.Vec2, length int) []mgl32.Vec2 {
+	if len(values) >= length {
+		return values[:length]
+	}
+	return append(values[:len(values):len(values)], make([]mgl32.Vec2, length-len(values))...)
+}
+
+//appendTriangle skips degenerate triangles, like the ones joining the rings of a strip, and
+//winds the rest counter clockwise around their vertex normals.
+func (m *Mesh) appendTriangle(a, b, c uint32) {
+	ab, ac, bc := m.Positions[b].Sub(m.Positions[a]), m.Positions[c].Sub(m.Positions[a]), m.Positions[c].Sub(m.Positions[b])
+	normal := ab.Cross(ac)
+	if longest := math32.Max(ab.LenSqr(), math32.Max(ac.LenSqr(), bc.LenSqr())); normal.Len() <= 1e-5*longest {
+		return
+	}
+	if len(m.Normals) == len(m.Positions) && normal.Dot(m.Normals[a].Add(m.Normals[b]).Add(m.Normals[c])) < 0 {
+		b, c = c, b
+	}
+	m.Indices = append(m.Indices, a, b, c)
+}
+
+//GetCircleMesh is GetCircleVertices3 as a mesh facing up.
+func GetCircleMesh(r float32, vertices int) *Mesh {
+	circle := GetCircleVertices3(r, vertices)
+	mesh := &Mesh{}
+	mesh.AppendFan(circle, GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(circle)), GetCircleTextureCoords(vertices))
+	return mesh
+}
+
+//GetRingMesh is GetRingVerticies3 as a mesh facing up.
+func GetRingMesh(rIn float32, rOut float32, vertices int) *Mesh {
+	ring := GetRingVerticies3(rIn, rOut, vertices)
+	mesh := &Mesh{}
+	mesh.AppendStrip(ring, GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(ring)), GetRingTextureCoords(rIn, rOut, vertices))
+	return mesh
+}
+
+//GetCylinderMesh joins the side, top and bottom of GetCylinderVertices3 in one mesh.
+func GetCylinderMesh(h float32, rBottom float32, rTop float32, vertices int) *Mesh {
+	side, top, bottom := GetCylinderVertices3(h, rBottom, rTop, vertices)
+	sideNormals, topNormals, bottomNormals := GetCylinderNormals3(h, rBottom, rTop, vertices)
+	sideUVs, topUVs, bottomUVs := GetCylinderTextureCoords(h, rBottom, rTop, vertices)
+	mesh := &Mesh{}
+	mesh.AppendStrip(side, sideNormals, sideUVs)
+	mesh.AppendFan(top, topNormals, topUVs)
+	mesh.AppendFan(bottom, bottomNormals, bottomUVs)
+	return mesh
+}
+
+//GetPipeMesh joins the sides, top and bottom of GetPipeVertices3 in one mesh.
+func GetPipeMesh(h float32, rIn float32, rOut float32, vertices int) *Mesh {
+	sideIn, sideOut, top, bottom := GetPipeVertices3(h, rIn, rOut, vertices)
+	sideInNormals, sideOutNormals, topNormals, bottomNormals := GetPipeNormals3(h, rIn, rOut, vertices)
+	sideInUVs, sideOutUVs, topUVs, bottomUVs := GetPipeTextureCoords(h, rIn, rOut, vertices)
+	mesh := &Mesh{}
+	mesh.AppendStrip(sideIn, sideInNormals, sideInUVs)
+	mesh.AppendStrip(sideOut, sideOutNormals, sideOutUVs)
+	mesh.AppendStrip(top, topNormals, topUVs)
+	mesh.AppendStrip(bottom, bottomNormals, bottomUVs)
+	return mesh
+}
+
+//GetSemiSphereMesh joins the side, top and bottom of GetSemiSphereVertices3 in one mesh.
+func GetSemiSphereMesh(r float32, vertices int) *Mesh {
+	side, top, bottom := GetSemiSphereVertices3(r, vertices)
+	sideNormals, topNormals, bottomNormals := GetSemiSphereNormals3(r, vertices)
+	sideUVs, topUVs, bottomUVs := GetSemiSphereTextureCoords(r, vertices)
+	mesh := &Mesh{}
+	mesh.AppendStrip(side, sideNormals, sideUVs)
+	mesh.AppendFan(top, topNormals, topUVs)
+	mesh.AppendFan(bottom, bottomNormals, bottomUVs)
+	return mesh
+}
+
+//GetSphereMesh joins the side, top and bottom of GetSphereVertices3 in one mesh.
+func GetSphereMesh(r float32, numVertex int) *Mesh {
+	side, top, bottom := GetSphereVertices3(r, numVertex)
+	sideNormals, topNormals, bottomNormals := GetSphereNormals3(r, numVertex)
+	sideUVs, topUVs, bottomUVs := GetSphereTextureCoords(r, numVertex)
+	mesh := &Mesh{}
+	mesh.AppendStrip(side, sideNormals, sideUVs)
+	mesh.AppendFan(top, topNormals, topUVs)
+	mesh.AppendFan(bottom, bottomNormals, bottomUVs)
+	return mesh
+}
+
+//GetCapsuleMesh joins the side, top and bottom of GetCapsuleVertices3 in one mesh.
+func GetCapsuleMesh(h float32, rBottom float32, rTop float32, vertices int) *Mesh {
+	side, top, bottom := GetCapsuleVertices3(h, rBottom, rTop, vertices)
+	sideNormals, topNormals, bottomNormals := GetCapsuleNormals3(h, rBottom, rTop, vertices)
+	sideUVs, topUVs, bottomUVs := GetCapsuleTextureCoords(h, rBottom, rTop, vertices)
+	mesh := &Mesh{}
+	mesh.AppendStrip(side, sideNormals, sideUVs)
+	mesh.AppendFan(top, topNormals, topUVs)
+	mesh.AppendFan(bottom, bottomNormals, bottomUVs)
+	return mesh
+}
+
+//GetCubicHexahedronMesh is GetCubicHexahedronVertices3 as a mesh with flat normals.
+func GetCubicHexahedronMesh(X, Y, Z float32) *Mesh {
+	vertices := GetCubicHexahedronVertices3(X, Y, Z)
+	indices := make([]uint32, len(vertices))
+	for i := range indices {
+		indices[i] = uint32(i)
+	}
+	return NewMesh(vertices, GetCubicHexahedronNormals3(X, Y, Z), GetCubicHexahedronTextureCoords(X, Y, Z), indices)
+}
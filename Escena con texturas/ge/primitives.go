@@ -17,6 +17,15 @@ func GetCircleVertices3(r float32, vertices int) (unitCircleVertices []mgl32.Vec
 	return
 }
 
+//GetCircleTextureCoords maps the vertices of GetCircleVertices3 to the unit square, with the
+//centre at (0.5, 0.5).
+func GetCircleTextureCoords(vertices int) (tCoords []mgl32.Vec2) {
+	for _, v := range GetCircleVertices3(0.5, vertices) {
+		tCoords = append(tCoords, mgl32.Vec2{v.X() + 0.5, v.Z() + 0.5})
+	}
+	return
+}
+
 //GetRingVerticies3 ...
 func GetRingVerticies3(rIn float32, rOut float32, vertices int) (ring []mgl32.Vec3) {
 	in := GetCircleVertices3(rIn, vertices)
@@ -28,6 +37,15 @@ func GetRingVerticies3(rIn float32, rOut float32, vertices int) (ring []mgl32.Ve
 	return
 }
 
+//GetRingTextureCoords maps the vertices of GetRingVerticies3 to the unit square, like
+//GetCircleTextureCoords does for a circle of radius rOut.
+func GetRingTextureCoords(rIn float32, rOut float32, vertices int) (tCoords []mgl32.Vec2) {
+	for _, v := range GetRingVerticies3(0.5*rIn/rOut, 0.5, vertices) {
+		tCoords = append(tCoords, mgl32.Vec2{v.X() + 0.5, v.Z() + 0.5})
+	}
+	return
+}
+
 //GetCylinderVertices3 ...
 func GetCylinderVertices3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
 	var slices int
@@ -59,6 +77,32 @@ func GetCylinderVertices3(h float32, rBottom float32, rTop float32, vertices int
 	return
 }
 
+//GetCylinderNormals3 returns smooth normals for the vertices of GetCylinderVertices3, tilted
+//along the slope of the side when rBottom and rTop differ.
+func GetCylinderNormals3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetCylinderVertices3(h, rBottom, rTop, vertices)
+	slope := (rBottom - rTop) / h
+	for i := range sideVertices {
+		angle := getStripU(i, vertices) * 2 * math32.Pi
+		side = append(side, mgl32.Vec3{math32.Cos(angle), slope, math32.Sin(angle)}.Normalize())
+	}
+	top = GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(topVertices))
+	bottom = GetConstantNormals3(mgl32.Vec3{0, -1, 0}, len(bottomVertices))
+	return
+}
+
+//GetCylinderTextureCoords wraps the unit square once around the side of GetCylinderVertices3
+//and maps the caps like GetCircleTextureCoords.
+func GetCylinderTextureCoords(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, _, _ := GetCylinderVertices3(h, rBottom, rTop, vertices)
+	for i, v := range sideVertices {
+		side = append(side, mgl32.Vec2{getStripU(i, vertices), v.Y() / h})
+	}
+	top = GetCircleTextureCoords(vertices)
+	bottom = GetCircleTextureCoords(vertices)
+	return
+}
+
 //GetPipeVertices3 ...
 func GetPipeVertices3(h float32, rIn float32, rOut float32, vertices int) (sideIn, sideOut, top, bottom []mgl32.Vec3) {
 	var slices int
@@ -82,6 +126,33 @@ func GetPipeVertices3(h float32, rIn float32, rOut float32, vertices int) (sideI
 	return
 }
 
+//GetPipeNormals3 returns normals for the vertices of GetPipeVertices3, pointing inwards on the
+//inner side and outwards on the outer one.
+func GetPipeNormals3(h float32, rIn float32, rOut float32, vertices int) (sideIn, sideOut, top, bottom []mgl32.Vec3) {
+	sideInVertices, _, topVertices, bottomVertices := GetPipeVertices3(h, rIn, rOut, vertices)
+	for i := range sideInVertices {
+		angle := getStripU(i, vertices) * 2 * math32.Pi
+		sideOut = append(sideOut, mgl32.Vec3{math32.Cos(angle), 0, math32.Sin(angle)})
+		sideIn = append(sideIn, mgl32.Vec3{-math32.Cos(angle), 0, -math32.Sin(angle)})
+	}
+	top = GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(topVertices))
+	bottom = GetConstantNormals3(mgl32.Vec3{0, -1, 0}, len(bottomVertices))
+	return
+}
+
+//GetPipeTextureCoords wraps the unit square around both sides of GetPipeVertices3 and maps
+//the rings like GetRingTextureCoords.
+func GetPipeTextureCoords(h float32, rIn float32, rOut float32, vertices int) (sideIn, sideOut, top, bottom []mgl32.Vec2) {
+	sideInVertices, _, _, _ := GetPipeVertices3(h, rIn, rOut, vertices)
+	for i, v := range sideInVertices {
+		sideIn = append(sideIn, mgl32.Vec2{getStripU(i, vertices), v.Y() / h})
+	}
+	sideOut = sideIn
+	top = GetRingTextureCoords(rIn, rOut, vertices)
+	bottom = top
+	return
+}
+
 //GetSemiSphereVertices3 ...
 func GetSemiSphereVertices3(r float32, vertices int) (side, top, bottom []mgl32.Vec3) {
 	auxR := r
@@ -107,6 +178,32 @@ func GetSemiSphereVertices3(r float32, vertices int) (side, top, bottom []mgl32.
 	return
 }
 
+//GetSemiSphereNormals3 returns smooth normals for the vertices of GetSemiSphereVertices3, the
+//flat bottom faces down.
+func GetSemiSphereNormals3(r float32, vertices int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetSemiSphereVertices3(r, vertices)
+	side = getSphereNormals(sideVertices, mgl32.Vec3{})
+	top = getSphereNormals(topVertices, mgl32.Vec3{})
+	bottom = GetConstantNormals3(mgl32.Vec3{0, -1, 0}, len(bottomVertices))
+	return
+}
+
+//GetSemiSphereTextureCoords maps the vertices of GetSemiSphereVertices3 by longitude and
+//latitude, from the rim at v = 0 to the pole at v = 1. The bottom is mapped like
+//GetCircleTextureCoords.
+func GetSemiSphereTextureCoords(r float32, vertices int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, topVertices, _ := GetSemiSphereVertices3(r, vertices)
+	latitude := func(v mgl32.Vec3) float32 {
+		return getLatitude(v.Y(), r) / (math32.Pi / 2)
+	}
+	for i, v := range sideVertices {
+		side = append(side, mgl32.Vec2{getStripU(i, vertices), latitude(v)})
+	}
+	top = getFanTextureCoords(topVertices, vertices, latitude)
+	bottom = GetCircleTextureCoords(vertices)
+	return
+}
+
 //GetSphereVertices3 ...
 func GetSphereVertices3(r float32, numVertex int) (side, top, bottom []mgl32.Vec3) {
 	semiSphere, top, _ := GetSemiSphereVertices3(r, numVertex)
@@ -123,6 +220,34 @@ func GetSphereVertices3(r float32, numVertex int) (side, top, bottom []mgl32.Vec
 	return
 }
 
+//GetSphereNormals3 returns smooth normals for the vertices of GetSphereVertices3.
+func GetSphereNormals3(r float32, numVertex int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetSphereVertices3(r, numVertex)
+	center := mgl32.Vec3{0, r, 0}
+	return getSphereNormals(sideVertices, center), getSphereNormals(topVertices, center), getSphereNormals(bottomVertices, center)
+}
+
+//GetSphereTextureCoords maps the vertices of GetSphereVertices3 by longitude and latitude,
+//from the bottom pole at v = 0 to the top one at v = 1.
+func GetSphereTextureCoords(r float32, numVertex int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, topVertices, bottomVertices := GetSphereVertices3(r, numVertex)
+	latitude := func(v mgl32.Vec3) float32 {
+		return 0.5 + getLatitude(v.Y()-r, r)/math32.Pi
+	}
+	// the side is the bottom half reversed followed by the top half
+	half := len(sideVertices) / 2
+	for i, v := range sideVertices {
+		column := i - half
+		if i < half {
+			column = half - 1 - i
+		}
+		side = append(side, mgl32.Vec2{getStripU(column, numVertex), latitude(v)})
+	}
+	top = getFanTextureCoords(topVertices, numVertex, latitude)
+	bottom = getFanTextureCoords(bottomVertices, numVertex, latitude)
+	return
+}
+
 //GetCapsuleVertices3 ...
 func GetCapsuleVertices3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
 	sideTemp, _, _ := GetCylinderVertices3(h-rBottom-rTop, rBottom, rTop, vertices)
@@ -142,6 +267,42 @@ func GetCapsuleVertices3(h float32, rBottom float32, rTop float32, vertices int)
 	return
 }
 
+//GetCapsuleNormals3 returns smooth normals for the vertices of GetCapsuleVertices3.
+func GetCapsuleNormals3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetCapsuleVertices3(h, rBottom, rTop, vertices)
+	bottomSide, _, _ := GetSemiSphereVertices3(rBottom, vertices)
+	cylinderSide, _, _ := GetCylinderNormals3(h-rBottom-rTop, rBottom, rTop, vertices)
+	bottomCenter, topCenter := mgl32.Vec3{0, rBottom, 0}, mgl32.Vec3{0, h - rTop, 0}
+
+	side = append(side, getSphereNormals(sideVertices[:len(bottomSide)], bottomCenter)...)
+	side = append(side, cylinderSide...)
+	side = append(side, getSphereNormals(sideVertices[len(bottomSide)+len(cylinderSide):], topCenter)...)
+	top = getSphereNormals(topVertices, topCenter)
+	bottom = getSphereNormals(bottomVertices, bottomCenter)
+	return
+}
+
+//GetCapsuleTextureCoords wraps the unit square once around GetCapsuleVertices3, from the
+//bottom pole at v = 0 to the top one at v = 1.
+func GetCapsuleTextureCoords(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, topVertices, bottomVertices := GetCapsuleVertices3(h, rBottom, rTop, vertices)
+	bottomSide, _, _ := GetSemiSphereVertices3(rBottom, vertices)
+	height := func(v mgl32.Vec3) float32 {
+		return v.Y() / h
+	}
+	// the bottom cap side is reversed, the cylinder and top cap sides follow in order
+	for i, v := range sideVertices {
+		column := i - len(bottomSide)
+		if i < len(bottomSide) {
+			column = len(bottomSide) - 1 - i
+		}
+		side = append(side, mgl32.Vec2{getStripU(column, vertices), height(v)})
+	}
+	top = getFanTextureCoords(topVertices, vertices, height)
+	bottom = getFanTextureCoords(bottomVertices, vertices, height)
+	return
+}
+
 //GetCubicHexahedronVertices3 ...
 func GetCubicHexahedronVertices3(X, Y, Z float32) []mgl32.Vec3 {
 	var vertices = []mgl32.Vec3{
@@ -161,6 +322,12 @@ func GetCubicHexahedronVertices3(X, Y, Z float32) []mgl32.Vec3 {
 	return vertices
 }
 
+//GetCubicHexahedronNormals3 returns flat normals for the vertices of
+//GetCubicHexahedronVertices3.
+func GetCubicHexahedronNormals3(X, Y, Z float32) []mgl32.Vec3 {
+	return GetFlatNormals3(GetCubicHexahedronVertices3(X, Y, Z))
+}
+
 //GetCubicHexahedronTextureCoords ...
 func GetCubicHexahedronTextureCoords(X, Y, Z float32) []mgl32.Vec2 {
 	var vertices = []mgl32.Vec2{
@@ -204,3 +371,11 @@ func GetPlaneTextureCoords(h int, w int, l int) (vertices []mgl32.Vec2) {
 	}
 	return
 }
+
+//GetPlaneMesh is GetPlaneVertices3 as a mesh facing up.
+func GetPlaneMesh(h int, w int, l int) *Mesh {
+	vertices := GetPlaneVertices3(h, w, l)
+	mesh := &Mesh{}
+	mesh.AppendStrip(vertices, GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(vertices)), GetPlaneTextureCoords(h, w, l))
+	return mesh
+}
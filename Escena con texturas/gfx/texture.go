@@ -15,6 +15,8 @@ type Texture struct {
 	handle  uint32
 	target  uint32 // same target as gl.BindTexture(<this param>, ...)
 	texUnit uint32 // Texture unit that is currently bound to ex: gl.TEXTURE0
+	Width   int32
+	Height  int32
 }
 
 var errUnsupportedStride = errors.New("unsupported stride, only 32-bit colors supported")
@@ -37,6 +39,23 @@ func NewTextureFromFile(file string, wrapR, wrapS int32) (*Texture, error) {
 }
 
 func NewTexture(img image.Image, wrapR, wrapS int32) (*Texture, error) {
+	return newTexture(img, wrapR, wrapS, gl.REPEAT, gl.SRGB_ALPHA)
+}
+
+//NewTextureWrap is NewTexture with the wrap modes along s and t, the two axes of a 2D texture,
+//like the samplers of glTF give them.
+func NewTextureWrap(img image.Image, wrapS, wrapT int32) (*Texture, error) {
+	return newTexture(img, gl.REPEAT, wrapS, wrapT, gl.SRGB_ALPHA)
+}
+
+//NewDataTexture is NewTextureWrap for images holding data rather than colors,
+//like height maps: shaders sample the values as they are stored instead of
+//converting them from sRGB.
+func NewDataTexture(img image.Image, wrapS, wrapT int32) (*Texture, error) {
+	return newTexture(img, gl.REPEAT, wrapS, wrapT, gl.RGBA8)
+}
+
+func newTexture(img image.Image, wrapR, wrapS, wrapT int32, internalFmt int32) (*Texture, error) {
 	rgba := image.NewRGBA(img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), img, image.Pt(0, 0), draw.Src)
 	if rgba.Stride != rgba.Rect.Size().X*4 { // TODO-cs: why?
@@ -47,7 +66,6 @@ func NewTexture(img image.Image, wrapR, wrapS int32) (*Texture, error) {
 	gl.GenTextures(1, &handle)
 
 	target := uint32(gl.TEXTURE_2D)
-	internalFmt := int32(gl.SRGB_ALPHA)
 	format := uint32(gl.RGBA)
 	width := int32(rgba.Rect.Size().X)
 	height := int32(rgba.Rect.Size().Y)
@@ -57,6 +75,8 @@ func NewTexture(img image.Image, wrapR, wrapS int32) (*Texture, error) {
 	texture := Texture{
 		handle: handle,
 		target: target,
+		Width:  width,
+		Height: height,
 	}
 
 	texture.Bind(gl.TEXTURE0)
@@ -66,6 +86,7 @@ func NewTexture(img image.Image, wrapR, wrapS int32) (*Texture, error) {
 	// TODO-cs
 	gl.TexParameteri(texture.target, gl.TEXTURE_WRAP_R, wrapR)
 	gl.TexParameteri(texture.target, gl.TEXTURE_WRAP_S, wrapS)
+	gl.TexParameteri(texture.target, gl.TEXTURE_WRAP_T, wrapT)
 	gl.TexParameteri(texture.target, gl.TEXTURE_MIN_FILTER, gl.LINEAR) // minification filter
 	gl.TexParameteri(texture.target, gl.TEXTURE_MAG_FILTER, gl.LINEAR) // magnification filter
 
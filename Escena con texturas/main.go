@@ -128,23 +128,36 @@ func programLoop(window *win.Window) error {
 		panic(err.Error())
 	}
 
-	// Get primitive vertices and create VAOs
-	cubeVertices := ge.GetCubicHexahedronVertices3(1, 1, 1)
-	cubeTextureCoords := ge.GetCubicHexahedronTextureCoords(1, 1, 1)
-	cubeVAO := ge.CreateVAO(cubeVertices, cubeTextureCoords)
+	// Get primitive meshes and upload them
+	cubeMesh := ge.GetCubicHexahedronMesh(1, 1, 1)
+	if err := cubeMesh.Upload(); err != nil {
+		return err
+	}
+	defer cubeMesh.Delete()
 
-	sphereVertices, sphereVerticesT, sphereVerticesB := ge.GetSphereVertices3(1, 32)
-	sphereVAO, sphereVAOT, sphereVAOB := ge.CreateVAO(sphereVertices, []mgl32.Vec2{}), ge.CreateVAO(sphereVerticesT, []mgl32.Vec2{}), ge.CreateVAO(sphereVerticesB, []mgl32.Vec2{})
+	sphereMesh := ge.GetSphereMesh(1, 32)
+	if err := sphereMesh.Upload(); err != nil {
+		return err
+	}
+	defer sphereMesh.Delete()
 
-	cylinderVertices, cylinderVerticesT, cylinderVerticesB := ge.GetCylinderVertices3(1, 0.1, 0.1, 5)
-	cylinderVAO, cylinderVAOT, cylinderVAOB := ge.CreateVAO(cylinderVertices, []mgl32.Vec2{}), ge.CreateVAO(cylinderVerticesT, []mgl32.Vec2{}), ge.CreateVAO(cylinderVerticesB, []mgl32.Vec2{})
+	cylinderMesh := ge.GetCylinderMesh(1, 0.1, 0.1, 5)
+	if err := cylinderMesh.Upload(); err != nil {
+		return err
+	}
+	defer cylinderMesh.Delete()
 
-	PipeVerticesSI, PipeVerticesSO, PipeVerticesT, PipeVerticesSB := ge.GetPipeVertices3(0.75, 0.4, 0.5, 16)
-	PipeVAOSI, PipeVAOSO, PipeVAOT, PipeVAOB := ge.CreateVAO(PipeVerticesSI, []mgl32.Vec2{}), ge.CreateVAO(PipeVerticesSO, []mgl32.Vec2{}), ge.CreateVAO(PipeVerticesT, []mgl32.Vec2{}), ge.CreateVAO(PipeVerticesSB, []mgl32.Vec2{})
+	pipeMesh := ge.GetPipeMesh(0.75, 0.4, 0.5, 16)
+	if err := pipeMesh.Upload(); err != nil {
+		return err
+	}
+	defer pipeMesh.Delete()
 
-	planeVertices := ge.GetPlaneVertices3(12, 12, 1)
-	planeTextureCoords := ge.GetPlaneTextureCoords(12, 12, 1)
-	planeVAO := ge.CreateVAO(planeVertices, planeTextureCoords)
+	planeMesh := ge.GetPlaneMesh(12, 12, 1)
+	if err := planeMesh.Upload(); err != nil {
+		return err
+	}
+	defer planeMesh.Delete()
 
 	for !window.ShouldClose() {
 		window.StartFrame()
@@ -176,111 +189,52 @@ func programLoop(window *win.Window) error {
 		// Moon
 		moonTranslate := mgl32.Translate3D(-5, 3, 2)
 		gl.Uniform3f(colorUniformLocation, 0.850, 0.850, 0.850)
-		gl.BindVertexArray(sphereVAO)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &moonTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sphereVertices)))
-
-		gl.BindVertexArray(sphereVAOT)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &moonTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(sphereVerticesT)))
-
-		gl.BindVertexArray(sphereVAOB)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &moonTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(sphereVerticesB)))
+		sphereMesh.Draw()
 
 		// Well
 		wellTranslate := mgl32.Translate3D(1, 0, 1)
 
 		gl.Uniform3f(colorUniformLocation, 0.301, 0.301, 0.301)
-		gl.BindVertexArray(PipeVAOSI)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(PipeVerticesSI)))
-
-		gl.BindVertexArray(PipeVAOSO)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(PipeVerticesSO)))
-
-		gl.BindVertexArray(PipeVAOT)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(PipeVerticesT)))
-
-		gl.BindVertexArray(PipeVAOB)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTranslate[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(PipeVerticesSB)))
+		pipeMesh.Draw()
 
 		// Pillars
 		gl.Uniform3f(colorUniformLocation, 0.301, 0.149, 0)
 		wellTransform := wellTranslate.Mul4(mgl32.Translate3D(-0.55, 0, 0)).Mul4(mgl32.Scale3D(0.75, 2, 0.75))
 
-		gl.BindVertexArray(cylinderVAO)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cylinderVertices)))
-
-		gl.BindVertexArray(cylinderVAOT)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesT)))
-
-		gl.BindVertexArray(cylinderVAOB)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesB)))
+		cylinderMesh.Draw()
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0.55, 0, 0)).Mul4(mgl32.Scale3D(0.75, 2, 0.75))
 
-		gl.BindVertexArray(cylinderVAO)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cylinderVertices)))
-
-		gl.BindVertexArray(cylinderVAOT)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesT)))
-
-		gl.BindVertexArray(cylinderVAOB)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesB)))
+		cylinderMesh.Draw()
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0.55, 1.75, -0.15)).Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(90))).Mul4(mgl32.Scale3D(0.5, 0.3, 0.5))
 
-		gl.BindVertexArray(cylinderVAO)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cylinderVertices)))
-
-		gl.BindVertexArray(cylinderVAOT)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesT)))
-
-		gl.BindVertexArray(cylinderVAOB)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesB)))
+		cylinderMesh.Draw()
 
 		wellTransform = wellTransform.Mul4(mgl32.Translate3D(-2.2, 0, 0))
 
-		gl.BindVertexArray(cylinderVAO)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cylinderVertices)))
-
-		gl.BindVertexArray(cylinderVAOT)
-		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesT)))
-
-		gl.BindVertexArray(cylinderVAOB)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesB)))
+		cylinderMesh.Draw()
 
 		// Roof
 		gl.Uniform3f(colorUniformLocation, 0.623, 0.141, 0.078)
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0, 1.75, 0.2)).Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(45))).Mul4(mgl32.Scale3D(1.25, 0.1, 0.75))
 
-		gl.BindVertexArray(cubeVAO)
 		roofTexture.Bind(gl.TEXTURE0)
 		roofTexture.SetUniform(textureUniformLocation)
 
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
+		cubeMesh.Draw()
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0, 1.75, -0.2)).Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(-45))).Mul4(mgl32.Scale3D(1.25, 0.1, 0.75)).Mul4(mgl32.HomogRotate3DY(mgl32.DegToRad(180)))
 
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &wellTransform[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
+		cubeMesh.Draw()
 
 		roofTexture.UnBind()
 
@@ -292,53 +246,40 @@ func programLoop(window *win.Window) error {
 			// Trunk
 			gl.Uniform3f(colorUniformLocation, 0.4, 0.2, 0)
 
-			gl.BindVertexArray(cylinderVAO)
 			gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &treeTranslate[0])
-			gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cylinderVertices)))
-
-			gl.BindVertexArray(cylinderVAOT)
-			gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &treeTranslate[0])
-			gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesT)))
-
-			gl.BindVertexArray(cylinderVAOB)
-			gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &treeTranslate[0])
-			gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(cylinderVerticesB)))
+			cylinderMesh.Draw()
 
 			// Leaves
 			scale1 := 1 - math32.Pow(math32.Abs(math32.Sin(float32(time*.7))), (1.3))*0.03
 			scale2 := 1 - math32.Pow(math32.Abs(math32.Cos(float32(time*.7))), (1.3))*0.03
 
-			gl.BindVertexArray(cubeVAO)
 			leavesTexture.Bind(gl.TEXTURE0)
 			leavesTexture.SetUniform(textureUniformLocation)
 
 			gl.Uniform3f(colorUniformLocation, 0, 0.9, 0)
 			worldTranslate := treeTranslate.Mul4(mgl32.Scale3D(1, 1*scale1, 1)).Mul4(mgl32.Translate3D(0, 1.25, -0.5))
 			gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &worldTranslate[0])
-			gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
+			cubeMesh.Draw()
 
 			gl.Uniform3f(colorUniformLocation, 0, 0.75, 0)
 			worldTranslate = treeTranslate.Mul4(mgl32.Scale3D(0.75, 0.75*scale1, 0.75)).Mul4(mgl32.Translate3D(0, 1.2, 0.4))
 			gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &worldTranslate[0])
-			gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
+			cubeMesh.Draw()
 
 			gl.Uniform3f(colorUniformLocation, 0, 0.5, 0)
 			worldTranslate = treeTranslate.Mul4(mgl32.Scale3D(0.8, 0.8*scale2, 0.8)).Mul4(mgl32.Translate3D(-0.5, 1.5, 0))
 			gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &worldTranslate[0])
-			gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
+			cubeMesh.Draw()
 
 			leavesTexture.UnBind()
 		}
 
-		gl.BindVertexArray(planeVAO)
 		grassTexture.Bind(gl.TEXTURE0)
 		grassTexture.SetUniform(textureUniformLocation)
 		gl.Uniform3f(colorUniformLocation, 0.4, 0.6, 0)
 		gl.UniformMatrix4fv(WorldUniformLocation, 1, false, &model[0])
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(planeVertices)))
+		planeMesh.Draw()
 		grassTexture.UnBind()
-
-		gl.BindVertexArray(0)
 	}
 
 	return nil
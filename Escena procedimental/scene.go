@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"git.maze.io/go/math32"
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
@@ -209,41 +210,16 @@ func getGlError(glHandle uint32, checkTrueParam uint32, getObjIvFn getObjIv,
 	return nil
 }
 
-/*
- * Creates the Vertex Array Object for a triangle.
- * indices is leftover from earlier samples and not used here.
- */
-func createVAO(vertices []mgl32.Vec3) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-
-	// Bind the Vertex Array Object first, then bind and set vertex buffer(s) and attribute pointers()
-	gl.BindVertexArray(VAO)
-
-	// copy vertices data into VBO (it needs to be bound first)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4*3, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	// size of one whole vertex (sum of attrib sizes)
-	var stride int32 = 3 * 4
-	var offset int = 0
-
-	// position
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.EnableVertexAttribArray(0)
-	offset += 3 * 4
-
-	// unbind the VAO (safe practice so we don't accidentally (mis)configure it later)
-	gl.BindVertexArray(0)
+// positionLayout is the input of vertexShaderSource, only positions
+var positionLayout = glcore.VertexLayout{
+	Attributes: []glcore.VertexAttribute{
+		{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT},
+	},
+}
 
-	return VAO
+// createVAO uploads vertices following positionLayout
+func createVAO(vertices []mgl32.Vec3) (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(positionLayout, glcore.VertexData{"position": vertices}, nil)
 }
 
 func programLoop(window *glfw.Window) error {
@@ -294,19 +270,74 @@ func programLoop(window *glfw.Window) error {
 	colorModel := gl.GetUniformLocation(program, gl.Str("objectColor\x00"))
 
 	cubeVertices := getCubeVertices(1)
-	cubeVAO := createVAO(cubeVertices)
+	cubeVAO, err := createVAO(cubeVertices)
+	if err != nil {
+		return err
+	}
+	defer cubeVAO.Delete()
 
 	sphereVertices, sphereTopVertices, sphereBottomVertices := getSphereVertices(1, 16, 4)
-	sphereVAO, sphereTopVAO, sphereBottomVAO := createVAO(sphereVertices), createVAO(sphereTopVertices), createVAO(sphereBottomVertices)
+	sphereVAO, err := createVAO(sphereVertices)
+	if err != nil {
+		return err
+	}
+	defer sphereVAO.Delete()
+	sphereTopVAO, err := createVAO(sphereTopVertices)
+	if err != nil {
+		return err
+	}
+	defer sphereTopVAO.Delete()
+	sphereBottomVAO, err := createVAO(sphereBottomVertices)
+	if err != nil {
+		return err
+	}
+	defer sphereBottomVAO.Delete()
 
 	sideVertices, topVertices, bottomVertices := getCylinderVertices(1, 0.1, 5)
-	sideVAO, topVAO, bottomVAO := createVAO(sideVertices), createVAO(topVertices), createVAO(bottomVertices)
+	sideVAO, err := createVAO(sideVertices)
+	if err != nil {
+		return err
+	}
+	defer sideVAO.Delete()
+	topVAO, err := createVAO(topVertices)
+	if err != nil {
+		return err
+	}
+	defer topVAO.Delete()
+	bottomVAO, err := createVAO(bottomVertices)
+	if err != nil {
+		return err
+	}
+	defer bottomVAO.Delete()
 
 	sideInVerticesPipe, sideOutVerticesPipe, topVerticesPipe, bottomVerticesPipe := getPipeVertices(0.75, 0.4, 0.5, 16)
-	sideInVAOPipe, sideOutVAOPipe, topVAOPipe, bottomVAOPipe := createVAO(sideInVerticesPipe), createVAO(sideOutVerticesPipe), createVAO(topVerticesPipe), createVAO(bottomVerticesPipe)
+	sideInVAOPipe, err := createVAO(sideInVerticesPipe)
+	if err != nil {
+		return err
+	}
+	defer sideInVAOPipe.Delete()
+	sideOutVAOPipe, err := createVAO(sideOutVerticesPipe)
+	if err != nil {
+		return err
+	}
+	defer sideOutVAOPipe.Delete()
+	topVAOPipe, err := createVAO(topVerticesPipe)
+	if err != nil {
+		return err
+	}
+	defer topVAOPipe.Delete()
+	bottomVAOPipe, err := createVAO(bottomVerticesPipe)
+	if err != nil {
+		return err
+	}
+	defer bottomVAOPipe.Delete()
 
 	planeVertices := getPlaneVertices(10, 10, 1)
-	planeVAO := createVAO(planeVertices)
+	planeVAO, err := createVAO(planeVertices)
+	if err != nil {
+		return err
+	}
+	defer planeVAO.Delete()
 
 	var treePositions = []mgl32.Vec3{
 		{-2.5, 0, -0.5},
@@ -333,15 +364,15 @@ func programLoop(window *glfw.Window) error {
 		//moon
 		moonTranslate := mgl32.Translate3D(0, 6, -5)
 		gl.Uniform3f(colorModel, 0.850, 0.850, 0.850)
-		gl.BindVertexArray(sphereVAO)
+		sphereVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &moonTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sphereVertices)))
 
-		gl.BindVertexArray(sphereTopVAO)
+		sphereTopVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &moonTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sphereTopVertices)))
 
-		gl.BindVertexArray(sphereBottomVAO)
+		sphereBottomVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &moonTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sphereBottomVertices)))
 
@@ -349,19 +380,19 @@ func programLoop(window *glfw.Window) error {
 		wellTranslate := mgl32.Translate3D(1, 0, 1)
 
 		gl.Uniform3f(colorModel, 0.301, 0.301, 0.301)
-		gl.BindVertexArray(sideInVAOPipe)
+		sideInVAOPipe.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideInVerticesPipe)))
 
-		gl.BindVertexArray(sideOutVAOPipe)
+		sideOutVAOPipe.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideOutVerticesPipe)))
 
-		gl.BindVertexArray(topVAOPipe)
+		topVAOPipe.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(topVerticesPipe)))
 
-		gl.BindVertexArray(bottomVAOPipe)
+		bottomVAOPipe.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTranslate[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(bottomVerticesPipe)))
 
@@ -369,57 +400,57 @@ func programLoop(window *glfw.Window) error {
 		gl.Uniform3f(colorModel, 0.301, 0.149, 0)
 		wellTransform := wellTranslate.Mul4(mgl32.Translate3D(-0.55, 0, 0)).Mul4(mgl32.Scale3D(0.75, 2, 0.75))
 
-		gl.BindVertexArray(sideVAO)
+		sideVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideVertices)))
 
-		gl.BindVertexArray(topVAO)
+		topVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(topVertices)))
 
-		gl.BindVertexArray(bottomVAO)
+		bottomVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(bottomVertices)))
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0.55, 0, 0)).Mul4(mgl32.Scale3D(0.75, 2, 0.75))
 
-		gl.BindVertexArray(sideVAO)
+		sideVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideVertices)))
 
-		gl.BindVertexArray(topVAO)
+		topVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(topVertices)))
 
-		gl.BindVertexArray(bottomVAO)
+		bottomVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(bottomVertices)))
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0.55, 1.75, -0.15)).Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(90))).Mul4(mgl32.Scale3D(0.5, 0.3, 0.5))
 
-		gl.BindVertexArray(sideVAO)
+		sideVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideVertices)))
 
-		gl.BindVertexArray(topVAO)
+		topVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(topVertices)))
 
-		gl.BindVertexArray(bottomVAO)
+		bottomVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(bottomVertices)))
 
 		wellTransform = wellTransform.Mul4(mgl32.Translate3D(-2.2, 0, 0))
 
-		gl.BindVertexArray(sideVAO)
+		sideVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideVertices)))
 
-		gl.BindVertexArray(topVAO)
+		topVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(topVertices)))
 
-		gl.BindVertexArray(bottomVAO)
+		bottomVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(bottomVertices)))
 
@@ -427,12 +458,12 @@ func programLoop(window *glfw.Window) error {
 		gl.Uniform3f(colorModel, 0.623, 0.141, 0.078)
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0, 1.75, 0.2)).Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(45))).Mul4(mgl32.Scale3D(1.25, 0.1, 0.75))
-		gl.BindVertexArray(cubeVAO)
+		cubeVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
 
 		wellTransform = wellTranslate.Mul4(mgl32.Translate3D(0, 1.75, -0.2)).Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(-45))).Mul4(mgl32.Scale3D(1.25, 0.1, 0.75))
-		gl.BindVertexArray(cubeVAO)
+		cubeVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &wellTransform[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
 
@@ -442,15 +473,15 @@ func programLoop(window *glfw.Window) error {
 
 			//trunk
 			gl.Uniform3f(colorModel, 0.4, 0.2, 0)
-			gl.BindVertexArray(sideVAO)
+			sideVAO.Bind()
 			gl.UniformMatrix4fv(modelUniform, 1, false, &treeTranslate[0])
 			gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(sideVertices)))
 
-			gl.BindVertexArray(topVAO)
+			topVAO.Bind()
 			gl.UniformMatrix4fv(modelUniform, 1, false, &treeTranslate[0])
 			gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(topVertices)))
 
-			gl.BindVertexArray(bottomVAO)
+			bottomVAO.Bind()
 			gl.UniformMatrix4fv(modelUniform, 1, false, &treeTranslate[0])
 			gl.DrawArrays(gl.TRIANGLE_FAN, 0, int32(len(bottomVertices)))
 
@@ -459,7 +490,7 @@ func programLoop(window *glfw.Window) error {
 			scale2 := 1 - math32.Abs(math32.Cos(time/2))*0.02
 
 			gl.Uniform3f(colorModel, 0, 0.9, 0)
-			gl.BindVertexArray(cubeVAO)
+			cubeVAO.Bind()
 			worldTranslate := treeTranslate.Mul4(mgl32.Scale3D(1, 1*scale1, 1)).Mul4(mgl32.Translate3D(0, 1.25, -0.5))
 			gl.UniformMatrix4fv(modelUniform, 1, false, &worldTranslate[0])
 			gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(cubeVertices)))
@@ -476,7 +507,7 @@ func programLoop(window *glfw.Window) error {
 		}
 
 		gl.Uniform3f(colorModel, 0.4, 0.6, 0)
-		gl.BindVertexArray(planeVAO)
+		planeVAO.Bind()
 		gl.UniformMatrix4fv(modelUniform, 1, false, &model[0])
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(planeVertices)))
 
@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"unsafe"
 
+	"github.com/StevenTarazona/glcore/ge"
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/kaitsubaka/glutils/gfx"
 	"github.com/kaitsubaka/glutils/win"
 
@@ -26,37 +28,13 @@ var (
 	frag = []string{"shaders/phong.frag", "shaders/gouraud.frag", "shaders/flat.frag"}
 )
 
-func createVAO(vertices, normals []float32, indices []uint32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	var NBO uint32
-	gl.GenBuffers(1, &NBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, NBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(normals)*4, gl.Ptr(normals), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(1)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	gl.BindVertexArray(0)
-
-	return VAO
+// newMeshVertexArray uploads the geometry of Sphere following ge.MeshLayout, the attribute
+// locations of the phong shaders
+func newMeshVertexArray(vertices, normals []float32, indices []uint32) (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(ge.MeshLayout, glcore.VertexData{
+		"position": vertices,
+		"normal":   normals,
+	}, indices)
 }
 
 type AnimationManager struct {
@@ -174,7 +152,11 @@ func programLoop(window *win.Window, shader int) error {
 
 	X_SEGMENTS := 30
 	Y_SEGMENTS := 30
-	VAO := createVAO(Sphere(X_SEGMENTS, Y_SEGMENTS))
+	VAO, err := newMeshVertexArray(Sphere(X_SEGMENTS, Y_SEGMENTS))
+	if err != nil {
+		return err
+	}
+	defer VAO.Delete()
 	lightVAO := VAO
 
 	animationCtl.Init()
@@ -199,7 +181,7 @@ func programLoop(window *win.Window, shader int) error {
 		gl.UniformMatrix4fv(viewUniformLocation, 1, false, &camera[0])
 		gl.UniformMatrix4fv(projectUniformLocation, 1, false, &projectTransform[0])
 
-		gl.BindVertexArray(VAO)
+		VAO.Bind()
 
 		// obj is colored, light is white
 		gl.Uniform3f(objectColorUniformLocation, .5, .0, .5)
@@ -215,7 +197,7 @@ func programLoop(window *win.Window, shader int) error {
 		// Draw the light obj after the other boxes using its separate shader program
 		// this means that we must re-bind any uniforms
 		lightProgram.Use()
-		gl.BindVertexArray(lightVAO)
+		lightVAO.Bind()
 		gl.UniformMatrix4fv(modelLightUniformLocation, 1, false, &lightTransform[0])
 		gl.UniformMatrix4fv(viewLightUniformLocation, 1, false, &camera[0])
 		gl.UniformMatrix4fv(projectLightUniformLocation, 1, false, &projectTransform[0])
@@ -7,6 +7,8 @@ import (
 	"unsafe"
 
 	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/ge"
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/kaitsubaka/glutils/gfx"
 	"github.com/kaitsubaka/glutils/win"
 
@@ -28,47 +30,14 @@ var (
 	}
 )
 
-func createVAO(vertices, normals, tCoords []float32, indices []uint32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	var NBO uint32
-	gl.GenBuffers(1, &NBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, NBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(normals)*4, gl.Ptr(normals), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(1)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	if len(tCoords) > 0 {
-		var TBO uint32
-		gl.GenBuffers(1, &TBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, TBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(tCoords)*4, gl.Ptr(tCoords), gl.STATIC_DRAW)
-		gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 2*4, gl.PtrOffset(0))
-		gl.EnableVertexAttribArray(2)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	}
-
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	gl.BindVertexArray(0)
-
-	return VAO
+// newMeshVertexArray uploads the geometry of Sphere, Square... following ge.MeshLayout, the
+// attribute locations of the phong shaders
+func newMeshVertexArray(vertices, normals, tCoords []float32, indices []uint32) (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(ge.MeshLayout, glcore.VertexData{
+		"position": vertices,
+		"normal":   normals,
+		"texCoord": tCoords,
+	}, indices)
 }
 
 func pointLightsUniformLocations(program *gfx.Program) [][]int32 {
@@ -180,9 +149,17 @@ func programLoop(window *win.Window) error {
 	// Geometry
 	xSegments := 30
 	ySegments := 30
-	VAO := createVAO(Sphere(xSegments, ySegments))
+	VAO, err := newMeshVertexArray(Sphere(xSegments, ySegments))
+	if err != nil {
+		return err
+	}
+	defer VAO.Delete()
 	lightVAO := VAO
-	skyVAO := createVAO(Cube(10, 10, 10))
+	skyVAO, err := newMeshVertexArray(Cube(10, 10, 10))
+	if err != nil {
+		return err
+	}
+	defer skyVAO.Delete()
 
 	// Scene and animation always needs to be after the model and buffers initialization
 	animationCtl := gfx.NewAnimationManager()
@@ -227,7 +204,7 @@ func programLoop(window *win.Window) error {
 		}
 
 		// render models
-		gl.BindVertexArray(VAO)
+		VAO.Bind()
 		earthTexture.Bind(gl.TEXTURE0)
 		earthTexture.SetUniform(textureUniformLocation)
 
@@ -243,7 +220,7 @@ func programLoop(window *win.Window) error {
 		gl.UniformMatrix4fv(projectSourceUniformLocation, 1, false, &projectTransform[0])
 		gl.UniformMatrix4fv(viewSourceUniformLocation, 1, false, &camera[0])
 		gl.Uniform3f(objectColorSourceUniformLocation, lightColor.X(), lightColor.Y(), lightColor.Z())
-		gl.BindVertexArray(lightVAO)
+		lightVAO.Bind()
 		for _, lp := range pointLightPositions {
 			cubeM := mgl32.Ident4()
 			cubeM = cubeM.Mul4(mgl32.Translate3D(lp.Elem())).Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
@@ -252,7 +229,7 @@ func programLoop(window *win.Window) error {
 		}
 		//gl.BindVertexArray(0)
 
-		gl.BindVertexArray(skyVAO)
+		skyVAO.Bind()
 		starsTexture.Bind(gl.TEXTURE0)
 		starsTexture.SetUniform(textureSourceUniformLocation)
 
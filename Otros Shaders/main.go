@@ -4,6 +4,7 @@ import (
 	"log"
 	"runtime"
 
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/kaitsubaka/glutils/gfx"
 	"github.com/kaitsubaka/glutils/win"
 
@@ -19,32 +20,28 @@ const (
 )
 
 var (
-	points = []float32{
-		-0.5, 0.5, 1.0, 0.0, 0.0, // top-left
-		0.5, 0.5, 0.0, 1.0, 0.0, // top-right
-		0.5, -0.5, 0.0, 0.0, 1.0, // bottom-right
-		-0.5, -0.5, 1.0, 1.0, 0., // bottom-left
+	positions = []mgl32.Vec2{
+		{-0.5, 0.5},  // top-left
+		{0.5, 0.5},   // top-right
+		{0.5, -0.5},  // bottom-right
+		{-0.5, -0.5}, // bottom-left
+	}
+	colors = []mgl32.Vec3{
+		{1.0, 0.0, 0.0},
+		{0.0, 1.0, 0.0},
+		{0.0, 0.0, 1.0},
+		{1.0, 1.0, 0.},
 	}
-)
-
-func createVAO(points []float32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(points)*4, gl.Ptr(points), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
-	gl.BindVertexArray(0)
 
-	return VAO
-}
+	// pointLayout is the input of shaders/geometry.vert
+	pointLayout = glcore.VertexLayout{
+		Attributes: []glcore.VertexAttribute{
+			{Name: "position", Location: 0, Size: 2, Type: gl.FLOAT},
+			{Name: "color", Location: 1, Size: 3, Type: gl.FLOAT},
+		},
+		Interleaved: true,
+	}
+)
 
 func programLoop(window *win.Window) error {
 
@@ -98,7 +95,11 @@ func programLoop(window *win.Window) error {
 	//gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
 
 	// Geometry
-	VAO := createVAO(points)
+	VAO, err := glcore.NewVertexArray(pointLayout, glcore.VertexData{"position": positions, "color": colors}, nil)
+	if err != nil {
+		return err
+	}
+	defer VAO.Delete()
 
 	// Scene and animation always needs to be after the model and buffers initialization
 	animationCtl := gfx.NewAnimationManager()
@@ -118,10 +119,7 @@ func programLoop(window *win.Window) error {
 		// You shall draw here
 		program.Use()
 		gl.UniformMatrix4fv(modelUniformLocation, 1, false, &model[0])
-		gl.BindVertexArray(VAO)
-		gl.DrawArrays(gl.POINTS, 0, 4)
-
-		gl.BindVertexArray(0)
+		VAO.Draw(gl.POINTS)
 	}
 
 	return nil
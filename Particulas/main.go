@@ -7,6 +7,8 @@ import (
 	"unsafe"
 
 	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/ge"
+	glcore "github.com/StevenTarazona/glcore/gfx"
 	"github.com/kaitsubaka/glutils/gfx"
 	"github.com/kaitsubaka/glutils/win"
 
@@ -34,75 +36,14 @@ var (
 	}
 )
 
-func createVAO(vertices, normals, tCoords []float32, indices []uint32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	var NBO uint32
-	gl.GenBuffers(1, &NBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, NBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(normals)*4, gl.Ptr(normals), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(1)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	if len(tCoords) > 0 {
-		var TBO uint32
-		gl.GenBuffers(1, &TBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, TBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(tCoords)*4, gl.Ptr(tCoords), gl.STATIC_DRAW)
-		gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 2*4, gl.PtrOffset(0))
-		gl.EnableVertexAttribArray(2)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	}
-
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	gl.BindVertexArray(0)
-
-	return VAO
-}
-
-func createParticleVAO(points []float32) (uint32, uint32) {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.BindVertexArray(VAO)
-
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(points)*4, gl.Ptr(points), gl.STATIC_DRAW)
-
-	// size of one whole vertex (sum of attrib sizes)
-	var stride int32 = 3*4 + 4*4 + 4
-	var offset int = 0
-
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.EnableVertexAttribArray(1)
-	offset += 3 * 4
-	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.EnableVertexAttribArray(2)
-	offset += 4 * 4
-	gl.VertexAttribPointer(2, 1, gl.FLOAT, false, stride, gl.PtrOffset(offset))
-	gl.BindVertexArray(0)
-
-	return VAO, VBO
+// newMeshVertexArray uploads the geometry of Sphere, Square... following ge.MeshLayout, the
+// attribute locations of the phong shaders
+func newMeshVertexArray(vertices, normals, tCoords []float32, indices []uint32) (*glcore.VertexArray, error) {
+	return glcore.NewVertexArray(ge.MeshLayout, glcore.VertexData{
+		"position": vertices,
+		"normal":   normals,
+		"texCoord": tCoords,
+	}, indices)
 }
 
 func pointLightsUL(program *gfx.Program) [][]int32 {
@@ -252,14 +193,30 @@ func programLoop(window *win.Window) error {
 	}
 
 	// Geometry
-	particle1VAO, particle1VBO := createParticleVAO(particles1.points)
-	particle2VAO, particle2VBO := createParticleVAO(particles2.points)
+	particle1VAO, err := particles1.NewVertexArray()
+	if err != nil {
+		return err
+	}
+	defer particle1VAO.Delete()
+	particle2VAO, err := particles2.NewVertexArray()
+	if err != nil {
+		return err
+	}
+	defer particle2VAO.Delete()
 
 	xLightSegments, yLighteSegments := 30, 30
-	lightVAO := createVAO(Sphere(xLightSegments, yLighteSegments))
+	lightVAO, err := newMeshVertexArray(Sphere(xLightSegments, yLighteSegments))
+	if err != nil {
+		return err
+	}
+	defer lightVAO.Delete()
 
 	xPlanSegments, yPlanSegments := 15, 15
-	planVAO := createVAO(Square(xPlanSegments, yPlanSegments, 1))
+	planVAO, err := newMeshVertexArray(Square(xPlanSegments, yPlanSegments, 1))
+	if err != nil {
+		return err
+	}
+	defer planVAO.Delete()
 
 	// Scene and animation always needs to be after the model and buffers initialization
 	animationCtl := gfx.NewAnimationManager()
@@ -311,7 +268,7 @@ func programLoop(window *win.Window) error {
 		// render models
 
 		//Plan
-		gl.BindVertexArray(planVAO)
+		planVAO.Bind()
 		planTexture.Bind(gl.TEXTURE0)
 		planTexture.SetUniform(texture0UL)
 		gl.UniformMatrix4fv(modelUL, 1, false, &model[0])
@@ -325,7 +282,7 @@ func programLoop(window *win.Window) error {
 		gl.UniformMatrix4fv(sourceViewUL, 1, false, &camera[0])
 
 		//Light objects
-		gl.BindVertexArray(lightVAO)
+		lightVAO.Bind()
 		for i, lp := range lightPositions {
 			lightTransform := model
 			if i > 1 {
@@ -350,17 +307,12 @@ func programLoop(window *win.Window) error {
 		gl.DepthMask(false)
 		gl.Enable(gl.BLEND)
 
-		gl.BindVertexArray(particle1VAO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, particle1VBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(particles1.points)*4, gl.Ptr(particles1.points), gl.STATIC_DRAW)
-		gl.DrawArrays(gl.POINTS, 0, int32(numParticles))
-		gl.BindVertexArray(0)
-
-		gl.BindVertexArray(particle2VAO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, particle2VBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(particles2.points)*4, gl.Ptr(particles2.points), gl.STATIC_DRAW)
-		gl.DrawArrays(gl.POINTS, 0, int32(numParticles))
-		gl.BindVertexArray(0)
+		if err := particles1.Draw(particle1VAO); err != nil {
+			return err
+		}
+		if err := particles2.Draw(particle2VAO); err != nil {
+			return err
+		}
 
 		gl.DepthMask(true)
 		gl.Disable(gl.BLEND)
@@ -98,6 +98,11 @@ func (c *FpsCamera) updateVectors() {
 	c.up = c.right.Cross(c.front).Normalize()
 }
 
+// Position returns the camera position in world coordinates.
+func (c *FpsCamera) Position() mgl32.Vec3 {
+	return c.pos
+}
+
 // GetCameraTransform gets the matrix to transform from world coordinates to
 // this camera's coordinates.
 func (camera *FpsCamera) GetTransform() mgl32.Mat4 {
@@ -0,0 +1,101 @@
+//Command meshexport writes the geometry of the ge generators to OBJ, binary
+//STL or PLY files without opening a window, to use the meshes in other
+//tools or to check a generator's output without a GPU.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/StevenTarazona/glcore/ge"
+	"github.com/StevenTarazona/glcore/noise"
+)
+
+func main() {
+	shape := flag.String("shape", "sphere", "circle, ring, cylinder, pipe, semisphere, sphere, capsule, cube, square or terrain")
+	out := flag.String("o", "", "output .obj, .stl or .ply file")
+	radius := flag.Float64("r", 1, "radius, or outer radius of rings and pipes")
+	innerRadius := flag.Float64("rin", .5, "inner radius of rings and pipes, or top radius of cylinders and capsules")
+	height := flag.Float64("h", 2, "height of cylinders, pipes, capsules and cubes")
+	vertices := flag.Int("vertices", 32, "vertices around round shapes")
+	hTiles := flag.Int("htiles", 200, "tiles across squares and terrains")
+	vTiles := flag.Int("vtiles", 50, "tiles along squares and terrains")
+	tileLength := flag.Float64("tilelength", .1, "side of every tile of squares and terrains")
+	heightMap := flag.String("heightmap", "", "image displacing the terrain")
+	noiseType := flag.String("noise", "", "perlin, simplex, worley, fbm or ridged noise displacing the terrain instead of -heightmap")
+	seed := flag.Int64("seed", 1, "noise seed")
+	noiseScale := flag.Float64("noisescale", .4, "noise cells per unit of terrain")
+	magnitude := flag.Float64("magnitude", 5, "height of the white parts of the height map")
+	flag.Parse()
+
+	if *out == "" {
+		log.Fatal("missing -o")
+	}
+	r, rIn, h := float32(*radius), float32(*innerRadius), float32(*height)
+
+	var mesh *ge.Mesh
+	switch *shape {
+	case "circle":
+		mesh = ge.GetCircleMesh(r, *vertices)
+	case "ring":
+		mesh = ge.GetRingMesh(rIn, r, *vertices)
+	case "cylinder":
+		mesh = ge.GetCylinderMesh(h, r, rIn, *vertices)
+	case "pipe":
+		mesh = ge.GetPipeMesh(h, rIn, r, *vertices)
+	case "semisphere":
+		mesh = ge.GetSemiSphereMesh(r, *vertices)
+	case "sphere":
+		mesh = ge.GetSphereMesh(r, *vertices)
+	case "capsule":
+		mesh = ge.GetCapsuleMesh(h, r, rIn, *vertices)
+	case "cube":
+		mesh = ge.GetCubicHexahedronMesh(r, h, r)
+	case "square":
+		mesh = ge.GetSquareMesh(*hTiles, *vTiles, float32(*tileLength))
+	case "terrain":
+		var terrain *ge.Terrain
+		switch {
+		case *noiseType != "":
+			source, err := getNoise(*noiseType, *seed)
+			if err != nil {
+				log.Fatal(err)
+			}
+			scale := float32(*noiseScale * *tileLength)
+			heights := noise.Heights(source, 0, 0, scale*float32(*hTiles), scale*float32(*vTiles), float32(*magnitude))
+			terrain = ge.NewTerrainFunc(*hTiles, *vTiles, float32(*tileLength), heights)
+		case *heightMap != "":
+			img, err := ge.LoadImage(*heightMap)
+			if err != nil {
+				log.Fatal(err)
+			}
+			terrain = ge.NewTerrain(*hTiles, *vTiles, float32(*tileLength), img, float32(*magnitude))
+		default:
+			log.Fatal("terrain needs -heightmap or -noise")
+		}
+		mesh = terrain.Mesh
+	default:
+		log.Fatalf("unknown shape %q", *shape)
+	}
+
+	if err := ge.SaveMesh(*out, mesh); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func getNoise(name string, seed int64) (noise.Source2, error) {
+	switch name {
+	case "perlin":
+		return noise.NewPerlin(seed), nil
+	case "simplex":
+		return noise.NewSimplex(seed), nil
+	case "worley":
+		return noise.NewWorley(seed), nil
+	case "fbm":
+		return noise.NewFBM(noise.NewSimplex(seed), 6), nil
+	case "ridged":
+		return noise.NewRidged(noise.NewSimplex(seed), 6), nil
+	}
+	return nil, fmt.Errorf("unknown noise %q", name)
+}
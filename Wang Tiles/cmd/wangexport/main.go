@@ -0,0 +1,78 @@
+//Command wangexport generates a Wang tile map without opening a window and
+//writes it as a PNG preview, as tile index grids and as a textured plane
+//mesh, so maps can be reviewed and diffed without a GPU. It fails if the map breaks any
+//adjacency rule.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/StevenTarazona/glcore/ge"
+)
+
+func main() {
+	tileSetFile := flag.String("tileset", "images/farm.json", "tile set description")
+	seed := flag.Int64("seed", 1, "generator seed")
+	hTiles := flag.Int("width", 40, "map width in tiles")
+	vTiles := flag.Int("height", 40, "map height in tiles")
+	tileSize := flag.Int("tilesize", 32, "size of every tile in the PNG, in pixels")
+	pngFile := flag.String("png", "", "write the composited map to this PNG file")
+	csvFile := flag.String("csv", "", "write the tile index grid to this CSV file")
+	jsonFile := flag.String("json", "", "write the tile index grid to this JSON file")
+	meshFile := flag.String("mesh", "", "write the textured plane to this .obj, .stl or .ply file")
+	tileLength := flag.Float64("tilelength", 1, "side of every tile in the mesh")
+	stats := flag.Bool("stats", false, "print violated edges and tile usage statistics")
+	flag.Parse()
+
+	tileSet, err := ge.LoadTileSet(*tileSetFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tiles, err := ge.NewWangGenerator(tileSet.AdjacencyList(), *seed).Generate(*hTiles, *vTiles)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := ge.ValidateTileMap(tiles, tileSet.AdjacencyList())
+	if *stats {
+		fmt.Print(report)
+	}
+	if !report.Valid() {
+		log.Fatalf("generated map has %d violated edges", len(report.Violations))
+	}
+
+	if *pngFile != "" {
+		atlas, err := ge.LoadImage(tileSet.Image)
+		if err != nil {
+			log.Fatal(err)
+		}
+		size := atlas.Bounds().Size()
+		img := ge.RenderTileMap(tiles, atlas, tileSet.TileCoords(size.X, size.Y), *tileSize)
+		if err := ge.SaveTileMapPNG(*pngFile, img); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *csvFile != "" {
+		if err := ge.SaveTileMapCSV(*csvFile, tiles); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *jsonFile != "" {
+		if err := ge.SaveTileMapJSON(*jsonFile, tiles); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *meshFile != "" {
+		atlas, err := ge.LoadImage(tileSet.Image)
+		if err != nil {
+			log.Fatal(err)
+		}
+		size := atlas.Bounds().Size()
+		mesh := ge.GetSquareTilesMesh(tiles, float32(*tileLength), tileSet.TileCoords(size.X, size.Y))
+		if err := ge.SaveMesh(*meshFile, mesh); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ge
+
+import (
+	"image"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//AtlasGrid describes tiles laid out on a regular grid inside an atlas image.
+type AtlasGrid struct {
+	// Pixel position of the top-left corner of the first cell
+	X int `json:"x"`
+	Y int `json:"y"`
+
+	CellWidth  int `json:"cellWidth"`
+	CellHeight int `json:"cellHeight"`
+
+	// Pixels between neighbouring cells
+	GutterX int `json:"gutterX"`
+	GutterY int `json:"gutterY"`
+
+	Rows int `json:"rows"`
+	Cols int `json:"cols"`
+
+	// Cells, numbered row by row from 0, that hold no tile
+	Skip []int `json:"skip"`
+}
+
+//Rects returns the pixel rectangle of every tile of the grid, row by row,
+//leaving out skipped cells.
+func (g AtlasGrid) Rects() (rects []image.Rectangle) {
+	skip := map[int]bool{}
+	for _, cell := range g.Skip {
+		skip[cell] = true
+	}
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			if skip[col+row*g.Cols] {
+				continue
+			}
+			min := image.Pt(g.X+col*(g.CellWidth+g.GutterX), g.Y+row*(g.CellHeight+g.GutterY))
+			rects = append(rects, image.Rectangle{Min: min, Max: min.Add(image.Pt(g.CellWidth, g.CellHeight))})
+		}
+	}
+	return
+}
+
+//SliceAtlas returns the texture coordinates of every tile of grid in an
+//atlas of width x height pixels, ready for GetSquareWangTiles. inset trims
+//that many pixels, possibly fractional, from every side of each tile so
+//linear filtering does not bleed the gutter into the tile edges.
+func SliceAtlas(width, height int, grid AtlasGrid, inset float32) [][]mgl32.Vec2 {
+	rects := grid.Rects()
+	tileCords := make([][]mgl32.Vec2, len(rects))
+	for i, rect := range rects {
+		tileCords[i] = getTileCoords(rect, inset, width, height)
+	}
+	return tileCords
+}
+
+//SliceAtlasImage is like SliceAtlas using the size of img.
+func SliceAtlasImage(img image.Image, grid AtlasGrid, inset float32) [][]mgl32.Vec2 {
+	size := img.Bounds().Size()
+	return SliceAtlas(size.X, size.Y, grid, inset)
+}
+
+//getTileCoords returns the four texture coordinates of a pixel rectangle in
+//the vertex order used by GetSquareTiles.
+func getTileCoords(rect image.Rectangle, inset float32, width, height int) []mgl32.Vec2 {
+	first := mgl32.Vec2{float32(rect.Min.X) + inset, float32(rect.Min.Y) + inset}
+	last := mgl32.Vec2{float32(rect.Max.X) - inset, float32(rect.Max.Y) - inset}
+	return Transform2([]mgl32.Vec2{
+		first,
+		{first.X(), last.Y()},
+		{last.X(), first.Y()},
+		last,
+	}, mgl32.Vec2{1 / float32(width), 1 / float32(height)})
+}
@@ -0,0 +1,116 @@
+package ge
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//Neighbour bits of a blob tile mask. A corner bit is only set when both
+//edges next to it are set too, which leaves 47 distinct masks.
+const (
+	BlobN = 1 << iota
+	BlobNE
+	BlobE
+	BlobSE
+	BlobS
+	BlobSW
+	BlobW
+	BlobNW
+)
+
+//CornerTiles picks a tile for every cell of a map authored as a grid of
+//corner colours. corners is indexed [v][h] and holds one more row and one
+//more column than the resulting tile grid; cell (h, v) gets a tile whose
+//NW, NE, SW and SE colours are corners[v][h], corners[v][h+1],
+//corners[v+1][h] and corners[v+1][h+1]. When several tiles match, one is
+//chosen with r, or the first one when r is nil.
+func (ts *TileSet) CornerTiles(corners [][]string, r *rand.Rand) ([][]int, error) {
+	if len(corners) < 2 || len(corners[0]) < 2 {
+		return nil, fmt.Errorf("corner tiles: corner grid needs at least 2x2 corners")
+	}
+	byCorners := map[[4]string][]int{}
+	for t, tile := range ts.Tiles {
+		key := [4]string{tile.NW, tile.NE, tile.SW, tile.SE}
+		byCorners[key] = append(byCorners[key], t)
+	}
+
+	vTiles, hTiles := len(corners)-1, len(corners[0])-1
+	tiles := make([][]int, vTiles)
+	for v := range tiles {
+		if len(corners[v]) != hTiles+1 || len(corners[v+1]) != hTiles+1 {
+			return nil, fmt.Errorf("corner tiles: corner rows %d and %d differ in length", v, v+1)
+		}
+		tiles[v] = make([]int, hTiles)
+		for h := range tiles[v] {
+			key := [4]string{corners[v][h], corners[v][h+1], corners[v+1][h], corners[v+1][h+1]}
+			options := byCorners[key]
+			if len(options) == 0 {
+				return nil, fmt.Errorf("corner tiles: no tile with corners %v for cell (%d, %d)", key, h, v)
+			}
+			tiles[v][h] = pickTile(options, r)
+		}
+	}
+	return tiles, nil
+}
+
+//BlobTiles picks a tile of a 47-tile blob set for every filled cell of a
+//painted map, indexed [v][h], from the mask of its filled neighbours. Cells
+//that are not filled get the background tile. When several tiles share a
+//mask, one is chosen with r, or the first one when r is nil.
+func (ts *TileSet) BlobTiles(filled [][]bool, background int, r *rand.Rand) ([][]int, error) {
+	if background < 0 || background >= len(ts.Tiles) {
+		return nil, fmt.Errorf("blob tiles: unknown background tile %d", background)
+	}
+	byMask := map[int][]int{}
+	for t, tile := range ts.Tiles {
+		if tile.Blob != nil {
+			byMask[*tile.Blob] = append(byMask[*tile.Blob], t)
+		}
+	}
+
+	isFilled := func(h, v int) bool {
+		return v >= 0 && v < len(filled) && h >= 0 && h < len(filled[v]) && filled[v][h]
+	}
+	tiles := make([][]int, len(filled))
+	for v := range filled {
+		tiles[v] = make([]int, len(filled[v]))
+		for h := range filled[v] {
+			if !filled[v][h] {
+				tiles[v][h] = background
+				continue
+			}
+			mask := GetBlobMask(
+				isFilled(h, v-1), isFilled(h+1, v-1), isFilled(h+1, v), isFilled(h+1, v+1),
+				isFilled(h, v+1), isFilled(h-1, v+1), isFilled(h-1, v), isFilled(h-1, v-1))
+			options := byMask[mask]
+			if len(options) == 0 {
+				return nil, fmt.Errorf("blob tiles: no tile with mask %d for cell (%d, %d)", mask, h, v)
+			}
+			tiles[v][h] = pickTile(options, r)
+		}
+	}
+	return tiles, nil
+}
+
+//GetBlobMask returns the blob mask for the given filled neighbours,
+//clockwise from north, dropping corners whose edges are not both filled.
+func GetBlobMask(n, ne, e, se, s, sw, w, nw bool) (mask int) {
+	neighbours := []bool{n, ne, e, se, s, sw, w, nw}
+	for i, set := range neighbours {
+		// odd bits are corners, only counted when both adjacent edges are set
+		if i%2 == 1 && !(neighbours[i-1] && neighbours[(i+1)%8]) {
+			continue
+		}
+		if set {
+			mask |= 1 << i
+		}
+	}
+	return
+}
+
+func pickTile(options []int, r *rand.Rand) int {
+	if r == nil {
+		return options[0]
+	}
+	return options[r.Intn(len(options))]
+}
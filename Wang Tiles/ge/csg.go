@@ -0,0 +1,420 @@
+package ge
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//csgEpsilon is how far from a plane a point can be and still be on it.
+const csgEpsilon = 1e-5
+
+//CSGUnion returns the space inside a or b. Both meshes must be closed with their triangles
+//counter clockwise seen from outside, like the primitives. Polygons are split on the planes of
+//a binary space partition tree of the other mesh, as in csg.js by Evan Wallace, and the pieces
+//keep the normals and uvs of the triangles they come from, interpolated where they are cut.
+//Vertices that other pieces were split at are added to their edges too, so the result is
+//welded without cracks.
+func CSGUnion(a, b *Mesh) *Mesh {
+	first, second := newCSGNode(getCSGPolygons(a)), newCSGNode(getCSGPolygons(b))
+	first.clipTo(second)
+	second.clipTo(first)
+	second.invert()
+	second.clipTo(first)
+	second.invert()
+	first.build(second.allPolygons())
+	return getCSGMesh(first.allPolygons())
+}
+
+//CSGDifference returns the space inside a and outside b, to cut windows, doors or tunnels.
+func CSGDifference(a, b *Mesh) *Mesh {
+	first, second := newCSGNode(getCSGPolygons(a)), newCSGNode(getCSGPolygons(b))
+	first.invert()
+	first.clipTo(second)
+	second.clipTo(first)
+	second.invert()
+	second.clipTo(first)
+	second.invert()
+	first.build(second.allPolygons())
+	first.invert()
+	return getCSGMesh(first.allPolygons())
+}
+
+//CSGIntersection returns the space inside both a and b.
+func CSGIntersection(a, b *Mesh) *Mesh {
+	first, second := newCSGNode(getCSGPolygons(a)), newCSGNode(getCSGPolygons(b))
+	first.invert()
+	second.clipTo(first)
+	second.invert()
+	first.clipTo(second)
+	second.clipTo(first)
+	first.build(second.allPolygons())
+	first.invert()
+	return getCSGMesh(first.allPolygons())
+}
+
+type csgVertex struct {
+	position mgl32.Vec3
+	normal   mgl32.Vec3
+	uv       mgl32.Vec2
+}
+
+func (v csgVertex) lerp(other csgVertex, t float32) csgVertex {
+	normal := v.normal.Add(other.normal.Sub(v.normal).Mul(t))
+	if normal.Len() > 0 {
+		normal = normal.Normalize()
+	}
+	return csgVertex{
+		position: v.position.Add(other.position.Sub(v.position).Mul(t)),
+		normal:   normal,
+		uv:       v.uv.Add(other.uv.Sub(v.uv).Mul(t)),
+	}
+}
+
+type csgPlane struct {
+	normal mgl32.Vec3
+	w      float32
+}
+
+func (p csgPlane) flip() csgPlane {
+	return csgPlane{p.normal.Mul(-1), -p.w}
+}
+
+//csgPolygon is a convex polygon counter clockwise around the normal of its plane.
+type csgPolygon struct {
+	vertices []csgVertex
+	plane    csgPlane
+}
+
+func (p csgPolygon) flip() csgPolygon {
+	vertices := make([]csgVertex, len(p.vertices))
+	for i, v := range p.vertices {
+		v.normal = v.normal.Mul(-1)
+		vertices[len(vertices)-1-i] = v
+	}
+	return csgPolygon{vertices, p.plane.flip()}
+}
+
+const (
+	csgCoplanar = 0
+	csgFront    = 1
+	csgBack     = 2
+	csgSpanning = 3
+)
+
+//split sorts polygon into the lists of the side of the plane it is on, cutting the ones that
+//span it in two.
+func (p csgPlane) split(polygon csgPolygon, coplanarFront, coplanarBack, front, back *[]csgPolygon) {
+	kind := 0
+	kinds := make([]int, len(polygon.vertices))
+	for i, v := range polygon.vertices {
+		t := p.normal.Dot(v.position) - p.w
+		switch {
+		case t < -csgEpsilon:
+			kinds[i] = csgBack
+		case t > csgEpsilon:
+			kinds[i] = csgFront
+		}
+		kind |= kinds[i]
+	}
+	switch kind {
+	case csgCoplanar:
+		if p.normal.Dot(polygon.plane.normal) > 0 {
+			*coplanarFront = append(*coplanarFront, polygon)
+		} else {
+			*coplanarBack = append(*coplanarBack, polygon)
+		}
+	case csgFront:
+		*front = append(*front, polygon)
+	case csgBack:
+		*back = append(*back, polygon)
+	case csgSpanning:
+		var f, b []csgVertex
+		for i, vi := range polygon.vertices {
+			j := (i + 1) % len(polygon.vertices)
+			vj := polygon.vertices[j]
+			if kinds[i] != csgBack {
+				f = append(f, vi)
+			}
+			if kinds[i] != csgFront {
+				b = append(b, vi)
+			}
+			if kinds[i]|kinds[j] == csgSpanning {
+				t := (p.w - p.normal.Dot(vi.position)) / p.normal.Dot(vj.position.Sub(vi.position))
+				v := vi.lerp(vj, t)
+				f = append(f, v)
+				b = append(b, v)
+			}
+		}
+		if len(f) >= 3 {
+			*front = append(*front, csgPolygon{f, polygon.plane})
+		}
+		if len(b) >= 3 {
+			*back = append(*back, csgPolygon{b, polygon.plane})
+		}
+	}
+}
+
+//csgNode is a node of a binary space partition tree, the polygons on its plane with the ones in
+//front and behind it in its children.
+type csgNode struct {
+	plane       *csgPlane
+	front, back *csgNode
+	polygons    []csgPolygon
+}
+
+func newCSGNode(polygons []csgPolygon) *csgNode {
+	node := &csgNode{}
+	node.build(polygons)
+	return node
+}
+
+//invert swaps the inside and the outside.
+func (n *csgNode) invert() {
+	for i, polygon := range n.polygons {
+		n.polygons[i] = polygon.flip()
+	}
+	if n.plane != nil {
+		flipped := n.plane.flip()
+		n.plane = &flipped
+	}
+	if n.front != nil {
+		n.front.invert()
+	}
+	if n.back != nil {
+		n.back.invert()
+	}
+	n.front, n.back = n.back, n.front
+}
+
+//clipPolygons removes the parts of polygons inside the tree.
+func (n *csgNode) clipPolygons(polygons []csgPolygon) []csgPolygon {
+	if n.plane == nil {
+		return append([]csgPolygon{}, polygons...)
+	}
+	var front, back []csgPolygon
+	for _, polygon := range polygons {
+		n.plane.split(polygon, &front, &back, &front, &back)
+	}
+	if n.front != nil {
+		front = n.front.clipPolygons(front)
+	}
+	if n.back != nil {
+		back = n.back.clipPolygons(back)
+	} else {
+		back = nil
+	}
+	return append(front, back...)
+}
+
+//clipTo removes the parts of the polygons of the tree inside other.
+func (n *csgNode) clipTo(other *csgNode) {
+	n.polygons = other.clipPolygons(n.polygons)
+	if n.front != nil {
+		n.front.clipTo(other)
+	}
+	if n.back != nil {
+		n.back.clipTo(other)
+	}
+}
+
+func (n *csgNode) allPolygons() []csgPolygon {
+	polygons := append([]csgPolygon{}, n.polygons...)
+	if n.front != nil {
+		polygons = append(polygons, n.front.allPolygons()...)
+	}
+	if n.back != nil {
+		polygons = append(polygons, n.back.allPolygons()...)
+	}
+	return polygons
+}
+
+//build adds polygons to the tree, splitting them on the planes of its nodes. The first polygon
+//gives the plane of a new node.
+func (n *csgNode) build(polygons []csgPolygon) {
+	if len(polygons) == 0 {
+		return
+	}
+	if n.plane == nil {
+		plane := polygons[0].plane
+		n.plane = &plane
+	}
+	var front, back []csgPolygon
+	for _, polygon := range polygons {
+		n.plane.split(polygon, &n.polygons, &n.polygons, &front, &back)
+	}
+	if len(front) > 0 {
+		if n.front == nil {
+			n.front = &csgNode{}
+		}
+		n.front.build(front)
+	}
+	if len(back) > 0 {
+		if n.back == nil {
+			n.back = &csgNode{}
+		}
+		n.back.build(back)
+	}
+}
+
+//getCSGPolygons turns the triangles of mesh into polygons, with the normals of their planes
+//where the mesh has none.
+func getCSGPolygons(mesh *Mesh) (polygons []csgPolygon) {
+	for i := 0; i+2 < len(mesh.Indices); i += 3 {
+		corners := []uint32{mesh.Indices[i], mesh.Indices[i+1], mesh.Indices[i+2]}
+		p0, p1, p2 := mesh.Positions[corners[0]], mesh.Positions[corners[1]], mesh.Positions[corners[2]]
+		normal := p1.Sub(p0).Cross(p2.Sub(p0))
+		if normal.Len() < 1e-12 {
+			continue
+		}
+		normal = normal.Normalize()
+		polygon := csgPolygon{plane: csgPlane{normal, normal.Dot(p0)}}
+		for _, corner := range corners {
+			v := csgVertex{position: mesh.Positions[corner], normal: normal}
+			if len(mesh.Normals) == len(mesh.Positions) {
+				v.normal = mesh.Normals[corner]
+			}
+			if len(mesh.UVs) == len(mesh.Positions) {
+				v.uv = mesh.UVs[corner]
+			}
+			polygon.vertices = append(polygon.vertices, v)
+		}
+		polygons = append(polygons, polygon)
+	}
+	return
+}
+
+//getCSGMesh triangulates polygons into a welded mesh. A vertex lying on the edge of another
+//polygon, where a neighbour was split and this one wasn't, is added to that edge first so the
+//triangles share it instead of leaving a crack.
+func getCSGMesh(polygons []csgPolygon) *Mesh {
+	// the vertices are bucketed in cells about the size of an edge to find the ones on edges
+	var points []mgl32.Vec3
+	edgeLength := float32(0)
+	edges := 0
+	for _, polygon := range polygons {
+		for i, v := range polygon.vertices {
+			points = append(points, v.position)
+			edgeLength += polygon.vertices[(i+1)%len(polygon.vertices)].position.Sub(v.position).Len()
+			edges++
+		}
+	}
+	mesh := NewMesh(nil, nil, nil, nil)
+	if edges == 0 {
+		return mesh
+	}
+	cellSize := math.Max(float64(edgeLength/float32(edges)), csgEpsilon)
+	cellOf := func(p mgl32.Vec3) [3]int64 {
+		return [3]int64{
+			int64(math.Floor(float64(p.X()) / cellSize)),
+			int64(math.Floor(float64(p.Y()) / cellSize)),
+			int64(math.Floor(float64(p.Z()) / cellSize)),
+		}
+	}
+	// a point cut from two polygons lands a rounding error apart on each, so points closer than
+	// csgEpsilon are snapped to the first one for the polygons to share it exactly
+	cells := map[[3]int64][]mgl32.Vec3{}
+	snapped := map[mgl32.Vec3]mgl32.Vec3{}
+	var unique []mgl32.Vec3
+	for _, p := range points {
+		if _, ok := snapped[p]; ok {
+			continue
+		}
+		snapped[p] = p
+		cell := cellOf(p)
+	search:
+		for x := cell[0] - 1; x <= cell[0]+1; x++ {
+			for y := cell[1] - 1; y <= cell[1]+1; y++ {
+				for z := cell[2] - 1; z <= cell[2]+1; z++ {
+					for _, q := range cells[[3]int64{x, y, z}] {
+						if isNear(p[:], q[:], csgEpsilon) {
+							snapped[p] = q
+							break search
+						}
+					}
+				}
+			}
+		}
+		if snapped[p] == p {
+			unique = append(unique, p)
+			cells[cell] = append(cells[cell], p)
+		}
+	}
+
+	for _, polygon := range polygons {
+		for i := range polygon.vertices {
+			polygon.vertices[i].position = snapped[polygon.vertices[i].position]
+		}
+		var vertices []csgVertex
+		for i, a := range polygon.vertices {
+			b := polygon.vertices[(i+1)%len(polygon.vertices)]
+			vertices = append(vertices, a)
+			edge := b.position.Sub(a.position)
+			length := edge.LenSqr()
+			if length == 0 {
+				continue
+			}
+			// vertices strictly inside the edge, sorted along it
+			var inside []csgEdgePoint
+			check := func(p mgl32.Vec3) {
+				t := p.Sub(a.position).Dot(edge) / length
+				if t > csgEpsilon && t < 1-csgEpsilon && a.position.Add(edge.Mul(t)).Sub(p).Len() < csgEpsilon {
+					inside = append(inside, csgEdgePoint{t, p})
+				}
+			}
+			min, max := cellOf(a.position), cellOf(b.position)
+			count := int64(1)
+			for axis := range min {
+				if min[axis] > max[axis] {
+					min[axis], max[axis] = max[axis], min[axis]
+				}
+				count *= max[axis] - min[axis] + 1
+			}
+			if count > int64(len(unique)) {
+				// long edges go through more cells than there are points
+				for _, p := range unique {
+					check(p)
+				}
+			} else {
+				for x := min[0]; x <= max[0]; x++ {
+					for y := min[1]; y <= max[1]; y++ {
+						for z := min[2]; z <= max[2]; z++ {
+							for _, p := range cells[[3]int64{x, y, z}] {
+								check(p)
+							}
+						}
+					}
+				}
+			}
+			sort.Slice(inside, func(i, j int) bool { return inside[i].t < inside[j].t })
+			for _, point := range inside {
+				v := a.lerp(b, point.t)
+				v.position = point.position
+				vertices = append(vertices, v)
+			}
+		}
+
+		positions := make([]mgl32.Vec3, len(vertices))
+		for i, v := range vertices {
+			positions[i] = v.position
+		}
+		first := uint32(len(mesh.Positions))
+		for _, v := range vertices {
+			mesh.Positions = append(mesh.Positions, v.position)
+			mesh.Normals = append(mesh.Normals, v.normal)
+			mesh.UVs = append(mesh.UVs, v.uv)
+		}
+		for _, triangle := range triangulatePolygon(positions, polygon.plane.normal) {
+			mesh.Indices = append(mesh.Indices, first+uint32(triangle[0]), first+uint32(triangle[1]), first+uint32(triangle[2]))
+		}
+	}
+	mesh.Weld(csgEpsilon)
+	return mesh
+}
+
+//csgEdgePoint is a vertex found on an edge, t along it.
+type csgEdgePoint struct {
+	t        float32
+	position mgl32.Vec3
+}
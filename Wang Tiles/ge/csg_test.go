@@ -0,0 +1,133 @@
+package ge
+
+import (
+	"testing"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//getCSGBox returns a closed 2x2x2 box centered on the origin.
+func getCSGBox() *Mesh {
+	box := GetCubicHexahedronMesh(2, 2, 2)
+	box.Transform(mgl32.Translate3D(0, -1, 0))
+	return box
+}
+
+//getCSGOperands returns the pairs of meshes the operations are tested on: a box with an
+//icosphere sticking out of a corner, and a box with a cylinder going through it.
+func getCSGOperands() []struct {
+	name string
+	a, b *Mesh
+} {
+	sphere := GetIcosphereMesh(.8, 2)
+	sphere.Transform(mgl32.Translate3D(1, .2, .1))
+	tunnel := GetCylinderMesh(4, .5, .5, 24)
+	tunnel.Transform(mgl32.Translate3D(.1, -2, 0))
+	return []struct {
+		name string
+		a, b *Mesh
+	}{
+		{"box and icosphere", getCSGBox(), sphere},
+		{"box and tunnel", getCSGBox(), tunnel},
+	}
+}
+
+func TestCSGIsClosedAndKeepsVolumes(t *testing.T) {
+	for _, operands := range getCSGOperands() {
+		volumeA, volumeB := getSignedVolume(operands.a), getSignedVolume(operands.b)
+		union := CSGUnion(operands.a, operands.b)
+		intersection := CSGIntersection(operands.a, operands.b)
+		difference := CSGDifference(operands.a, operands.b)
+		for _, result := range []struct {
+			name string
+			mesh *Mesh
+		}{{"union", union}, {"intersection", intersection}, {"difference", difference}} {
+			name := operands.name + " " + result.name
+			if len(result.mesh.Indices) == 0 {
+				t.Fatalf("%s is empty", name)
+			}
+			if open := countOpenEdges(result.mesh); open != 0 {
+				t.Errorf("%s has %d open edges", name, open)
+			}
+			if volume := getSignedVolume(result.mesh); volume <= 0 {
+				t.Errorf("%s faces inwards, signed volume %v", name, volume)
+			}
+		}
+		volumeUnion, volumeIntersection := getSignedVolume(union), getSignedVolume(intersection)
+		// every operand is inside the union and contains the intersection
+		if volumeIntersection >= math32.Min(volumeA, volumeB) || volumeUnion <= math32.Max(volumeA, volumeB) {
+			t.Errorf("%s: union %v and intersection %v of volumes %v and %v", operands.name, volumeUnion, volumeIntersection, volumeA, volumeB)
+		}
+		if sum := volumeUnion + volumeIntersection; math32.Abs(sum-volumeA-volumeB) > 1e-3*sum {
+			t.Errorf("%s: union and intersection add up to %v, want %v", operands.name, sum, volumeA+volumeB)
+		}
+		if volume := getSignedVolume(difference); math32.Abs(volume-(volumeA-volumeIntersection)) > 1e-3*volumeA {
+			t.Errorf("%s: difference has volume %v, want %v", operands.name, volume, volumeA-volumeIntersection)
+		}
+	}
+}
+
+//getInterpolated returns the normals and uvs mesh has at p, interpolated on each of the
+//triangles containing it.
+func getInterpolated(mesh *Mesh, p mgl32.Vec3) (normals []mgl32.Vec3, uvs []mgl32.Vec2) {
+	for i := 0; i+2 < len(mesh.Indices); i += 3 {
+		i0, i1, i2 := mesh.Indices[i], mesh.Indices[i+1], mesh.Indices[i+2]
+		a, b, c := mesh.Positions[i0], mesh.Positions[i1], mesh.Positions[i2]
+		normal := b.Sub(a).Cross(c.Sub(a))
+		area := normal.Len()
+		if area < 1e-12 {
+			continue
+		}
+		if math32.Abs(p.Sub(a).Dot(normal))/area > 1e-4 {
+			continue
+		}
+		u := c.Sub(b).Cross(p.Sub(b)).Dot(normal) / (area * area)
+		v := a.Sub(c).Cross(p.Sub(c)).Dot(normal) / (area * area)
+		w := 1 - u - v
+		if u < -1e-4 || v < -1e-4 || w < -1e-4 {
+			continue
+		}
+		interpolated := mesh.Normals[i0].Mul(u).Add(mesh.Normals[i1].Mul(v)).Add(mesh.Normals[i2].Mul(w))
+		normals = append(normals, interpolated.Normalize())
+		uvs = append(uvs, mesh.UVs[i0].Mul(u).Add(mesh.UVs[i1].Mul(v)).Add(mesh.UVs[i2].Mul(w)))
+	}
+	return
+}
+
+func TestCSGKeepsNormalsAndUVsOnCutFaces(t *testing.T) {
+	for _, operands := range getCSGOperands() {
+		// the faces of b are inverted in the difference, facing into the hole
+		inverted := NewMesh(operands.b.Positions, nil, operands.b.UVs, operands.b.Indices)
+		for _, normal := range operands.b.Normals {
+			inverted.Normals = append(inverted.Normals, normal.Mul(-1))
+		}
+		difference := CSGDifference(operands.a, operands.b)
+		original := map[mgl32.Vec3]bool{}
+		for _, p := range append(append([]mgl32.Vec3{}, operands.a.Positions...), operands.b.Positions...) {
+			original[p] = true
+		}
+		cut := 0
+		for i, p := range difference.Positions {
+			if !original[p] {
+				cut++
+			}
+			found := false
+			for _, source := range []*Mesh{operands.a, inverted} {
+				normals, uvs := getInterpolated(source, p)
+				for k := range normals {
+					// normals are renormalized at every cut, so they only keep their direction
+					if normals[k].Dot(difference.Normals[i]) > .99 && uvs[k].Sub(difference.UVs[i]).Len() < 1e-3 {
+						found = true
+					}
+				}
+			}
+			if !found {
+				t.Errorf("%s: vertex %v has normal %v and uv %v, which none of the faces it is on has", operands.name, p, difference.Normals[i], difference.UVs[i])
+			}
+		}
+		if cut == 0 {
+			t.Errorf("%s: no face was cut", operands.name)
+		}
+	}
+}
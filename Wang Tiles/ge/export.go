@@ -0,0 +1,182 @@
+package ge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//WriteOBJ writes the mesh as a Wavefront OBJ object. Texture coordinates are flipped back to
+//the OBJ convention, so LoadOBJ reads the mesh as it was written.
+func (m *Mesh) WriteOBJ(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "# %d vertices, %d triangles\n", len(m.Positions), len(m.Indices)/3)
+	for _, p := range m.Positions {
+		fmt.Fprintf(out, "v %g %g %g\n", p[0], p[1], p[2])
+	}
+	hasUVs, hasNormals := m.hasUVs(), m.hasNormals()
+	if hasUVs {
+		for _, uv := range m.UVs {
+			fmt.Fprintf(out, "vt %g %g\n", uv[0], 1-uv[1])
+		}
+	}
+	if hasNormals {
+		for _, n := range m.Normals {
+			fmt.Fprintf(out, "vn %g %g %g\n", n[0], n[1], n[2])
+		}
+	}
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		out.WriteString("f")
+		for _, index := range m.Indices[i : i+3] {
+			index++
+			switch {
+			case hasUVs && hasNormals:
+				fmt.Fprintf(out, " %d/%d/%d", index, index, index)
+			case hasUVs:
+				fmt.Fprintf(out, " %d/%d", index, index)
+			case hasNormals:
+				fmt.Fprintf(out, " %d//%d", index, index)
+			default:
+				fmt.Fprintf(out, " %d", index)
+			}
+		}
+		out.WriteString("\n")
+	}
+	return out.Flush()
+}
+
+//WriteSTL writes the mesh as a binary STL file. STL has no shared vertices, normals or
+//texture coordinates, every triangle is written with its face normal.
+func (m *Mesh) WriteSTL(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	header := make([]byte, 80)
+	copy(header, "binary STL written by ge")
+	out.Write(header)
+	binary.Write(out, binary.LittleEndian, uint32(len(m.Indices)/3))
+	triangle := make([]float32, 12)
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := m.Positions[m.Indices[i]], m.Positions[m.Indices[i+1]], m.Positions[m.Indices[i+2]]
+		normal := b.Sub(a).Cross(c.Sub(a))
+		if normal.Len() > 0 {
+			normal = normal.Normalize()
+		}
+		copy(triangle, normal[:])
+		copy(triangle[3:], a[:])
+		copy(triangle[6:], b[:])
+		copy(triangle[9:], c[:])
+		binary.Write(out, binary.LittleEndian, triangle)
+		// attribute byte count
+		binary.Write(out, binary.LittleEndian, uint16(0))
+	}
+	return out.Flush()
+}
+
+//WritePLY writes the mesh as a binary little endian PLY file, with normals and texture
+//coordinates, named s and t, when the mesh has them.
+func (m *Mesh) WritePLY(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	hasUVs, hasNormals := m.hasUVs(), m.hasNormals()
+	fmt.Fprintf(out, "ply\nformat binary_little_endian 1.0\ncomment written by ge\n")
+	fmt.Fprintf(out, "element vertex %d\n", len(m.Positions))
+	out.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if hasNormals {
+		out.WriteString("property float nx\nproperty float ny\nproperty float nz\n")
+	}
+	if hasUVs {
+		out.WriteString("property float s\nproperty float t\n")
+	}
+	fmt.Fprintf(out, "element face %d\n", len(m.Indices)/3)
+	out.WriteString("property list uchar uint vertex_indices\nend_header\n")
+
+	vertex := make([]byte, 0, 32)
+	for i, p := range m.Positions {
+		vertex = appendFloats(vertex[:0], p[:]...)
+		if hasNormals {
+			vertex = appendFloats(vertex, m.Normals[i][:]...)
+		}
+		if hasUVs {
+			vertex = appendFloats(vertex, m.UVs[i][:]...)
+		}
+		out.Write(vertex)
+	}
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		out.WriteByte(3)
+		binary.Write(out, binary.LittleEndian, m.Indices[i:i+3])
+	}
+	return out.Flush()
+}
+
+//SaveMesh writes mesh to file as OBJ, STL or PLY, picked by the extension of file.
+func SaveMesh(file string, mesh *Mesh) error {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".obj":
+		return SaveMeshOBJ(file, mesh)
+	case ".stl":
+		return SaveMeshSTL(file, mesh)
+	case ".ply":
+		return SaveMeshPLY(file, mesh)
+	}
+	return fmt.Errorf("%s: unknown mesh format, use .obj, .stl or .ply", file)
+}
+
+//SaveMeshOBJ writes mesh to file with WriteOBJ.
+func SaveMeshOBJ(file string, mesh *Mesh) error {
+	return writeFile(file, mesh.WriteOBJ)
+}
+
+//SaveMeshSTL writes mesh to file with WriteSTL.
+func SaveMeshSTL(file string, mesh *Mesh) error {
+	return writeFile(file, mesh.WriteSTL)
+}
+
+//SaveMeshPLY writes mesh to file with WritePLY.
+func SaveMeshPLY(file string, mesh *Mesh) error {
+	return writeFile(file, mesh.WritePLY)
+}
+
+//LoadImage decodes the PNG or JPEG image in file, like the height maps and atlases the tools
+//read.
+func LoadImage(file string) (image.Image, error) {
+	imgFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer imgFile.Close()
+
+	img, _, err := image.Decode(imgFile)
+	return img, err
+}
+
+func writeFile(file string, write func(w io.Writer) error) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := write(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (m *Mesh) hasNormals() bool {
+	return len(m.Normals) > 0 && len(m.Normals) == len(m.Positions)
+}
+
+func (m *Mesh) hasUVs() bool {
+	return len(m.UVs) > 0 && len(m.UVs) == len(m.Positions)
+}
+
+func appendFloats(data []byte, values ...float32) []byte {
+	for _, value := range values {
+		data = append(data, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(data[len(data)-4:], math.Float32bits(value))
+	}
+	return data
+}
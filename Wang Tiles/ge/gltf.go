@@ -0,0 +1,855 @@
+package ge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg" // glTF images
+	_ "image/png"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/StevenTarazona/glcore/gfx"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//MaxPointLights is NR_POINT_LIGHTS, the size of the pointLights array of shaders/phong_ml.frag.
+const MaxPointLights = 8
+
+//Scene is a glTF 2.0 scene loaded with LoadGLTF.
+type Scene struct {
+	// Nodes are the root nodes of the scene
+	Nodes     []*SceneNode
+	Meshes    []*SceneMesh
+	Materials []*PBRMaterial
+	// Cameras and Lights hold one entry per node that uses them, placed by the node transform
+	Cameras []*SceneCamera
+	Lights  []*ScenePointLight
+
+	textures []*sceneTexture
+}
+
+//SceneNode is a node of the scene hierarchy.
+type SceneNode struct {
+	Name string
+	// Local is the transform relative to the parent and World the one relative to the scene
+	Local    mgl32.Mat4
+	World    mgl32.Mat4
+	Children []*SceneNode
+	// Mesh is nil for nodes without geometry
+	Mesh *SceneMesh
+}
+
+//SceneMesh is a glTF mesh, one Mesh and material per primitive.
+type SceneMesh struct {
+	Name      string
+	Meshes    []*Mesh
+	Materials []*PBRMaterial
+}
+
+//PBRMaterial holds the metallic roughness factors of a glTF material.
+type PBRMaterial struct {
+	Name      string
+	BaseColor mgl32.Vec4
+	Metallic  float32
+	Roughness float32
+	Emissive  mgl32.Vec3
+	// Texture is the base color texture, created by Scene.Upload
+	Texture *gfx.Texture
+
+	baseColorTexture *sceneTexture
+}
+
+//SceneCamera is a glTF camera placed in the scene.
+type SceneCamera struct {
+	Name        string
+	Perspective bool
+	// YFov is in radians, AspectRatio is 0 when the viewport should decide
+	YFov, AspectRatio float32
+	// XMag and YMag are the half sizes of orthographic views
+	XMag, YMag  float32
+	ZNear, ZFar float32
+	// View is the inverse of the camera node transform
+	View     mgl32.Mat4
+	Position mgl32.Vec3
+}
+
+//ScenePointLight is a KHR_lights_punctual point light placed in the scene.
+type ScenePointLight struct {
+	Name      string
+	Color     mgl32.Vec3
+	Intensity float32
+	// Range is 0 when the light has no cut off distance
+	Range    float32
+	Position mgl32.Vec3
+}
+
+type sceneTexture struct {
+	image        image.Image
+	wrapS, wrapT int32
+	texture      *gfx.Texture
+}
+
+//LoadGLTF reads a .gltf file, with its buffers and images, or a binary .glb file. The default
+//scene is loaded, or every root node when there is none. Spot and directional lights are skipped.
+func LoadGLTF(file string) (*Scene, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	scene, err := readGLTF(data, filepath.Dir(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return scene, nil
+}
+
+//Upload uploads every mesh and creates the textures of the materials.
+func (s *Scene) Upload() error {
+	for _, texture := range s.textures {
+		if texture.texture != nil {
+			continue
+		}
+		var err error
+		if texture.texture, err = gfx.NewTextureWrap(texture.image, texture.wrapS, texture.wrapT); err != nil {
+			return err
+		}
+	}
+	for _, material := range s.Materials {
+		if material.baseColorTexture != nil {
+			material.Texture = material.baseColorTexture.texture
+		}
+	}
+	for _, mesh := range s.Meshes {
+		for _, primitive := range mesh.Meshes {
+			if err := primitive.Upload(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Draw draws every node below model. The world transform of each node is set at
+//modelUniformLocation, then bind is called with the material of each primitive, which may be
+//nil, so the caller can set the uniforms of its shader; textures are bound to TEXTURE0 before that.
+func (s *Scene) Draw(modelUniformLocation int32, model mgl32.Mat4, bind func(material *PBRMaterial)) {
+	var draw func(node *SceneNode)
+	draw = func(node *SceneNode) {
+		if node.Mesh != nil {
+			world := model.Mul4(node.World)
+			gl.UniformMatrix4fv(modelUniformLocation, 1, false, &world[0])
+			for i, primitive := range node.Mesh.Meshes {
+				material := node.Mesh.Materials[i]
+				if material != nil && material.Texture != nil {
+					material.Texture.Bind(gl.TEXTURE0)
+				}
+				if bind != nil {
+					bind(material)
+				}
+				primitive.Draw()
+				if material != nil && material.Texture != nil {
+					material.Texture.UnBind()
+				}
+			}
+		}
+		for _, child := range node.Children {
+			draw(child)
+		}
+	}
+	for _, node := range s.Nodes {
+		draw(node)
+	}
+}
+
+//SetPointLights sets numLights and the pointLights array of phong_ml.frag from the first
+//MaxPointLights lights of the scene. The light color is scaled by its intensity and the
+//attenuation is derived from its range.
+func (s *Scene) SetPointLights(program *gfx.Program) {
+	count := len(s.Lights)
+	if count > MaxPointLights {
+		count = MaxPointLights
+	}
+	gl.Uniform1i(program.GetUniformLocation("numLights"), int32(count))
+	for i, light := range s.Lights[:count] {
+		uniform := func(name string) int32 {
+			return program.GetUniformLocation(fmt.Sprint("pointLights[", i, "].", name))
+		}
+		color := light.Color.Mul(light.Intensity)
+		linear, quadratic := float32(0.09), float32(0.032)
+		if light.Range > 0 {
+			linear, quadratic = 4.5/light.Range, 75/(light.Range*light.Range)
+		}
+		gl.Uniform3fv(uniform("position"), 1, &light.Position[0])
+		gl.Uniform3fv(uniform("lightColor"), 1, &color[0])
+		gl.Uniform3f(uniform("ambient"), .01, .01, .01)
+		gl.Uniform3f(uniform("diffuse"), 1, 1, 1)
+		gl.Uniform3f(uniform("specular"), 1, 1, 1)
+		gl.Uniform1f(uniform("constant"), 1)
+		gl.Uniform1f(uniform("linear"), linear)
+		gl.Uniform1f(uniform("quadratic"), quadratic)
+	}
+}
+
+//Delete releases the meshes of the scene.
+func (s *Scene) Delete() {
+	for _, mesh := range s.Meshes {
+		for _, primitive := range mesh.Meshes {
+			primitive.Delete()
+		}
+	}
+}
+
+//Projection returns the projection matrix of the camera, aspect is used when the camera has none.
+func (c *SceneCamera) Projection(aspect float32) mgl32.Mat4 {
+	if c.AspectRatio > 0 {
+		aspect = c.AspectRatio
+	}
+	if !c.Perspective {
+		return mgl32.Ortho(-c.XMag, c.XMag, -c.YMag, c.YMag, c.ZNear, c.ZFar)
+	}
+	zFar := c.ZFar
+	if zFar == 0 {
+		// infinite projections are approximated with a far plane
+		zFar = c.ZNear * 1e6
+	}
+	return mgl32.Perspective(c.YFov, aspect, c.ZNear, zFar)
+}
+
+type gltfDocument struct {
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Name        string    `json:"name"`
+		Children    []int     `json:"children"`
+		Mesh        *int      `json:"mesh"`
+		Camera      *int      `json:"camera"`
+		Matrix      []float32 `json:"matrix"`
+		Translation []float32 `json:"translation"`
+		Rotation    []float32 `json:"rotation"`
+		Scale       []float32 `json:"scale"`
+		Extensions  struct {
+			Lights *struct {
+				Light int `json:"light"`
+			} `json:"KHR_lights_punctual"`
+		} `json:"extensions"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Materials []struct {
+		Name string `json:"name"`
+		PBR  struct {
+			BaseColorFactor  []float32 `json:"baseColorFactor"`
+			MetallicFactor   *float32  `json:"metallicFactor"`
+			RoughnessFactor  *float32  `json:"roughnessFactor"`
+			BaseColorTexture *struct {
+				Index int `json:"index"`
+			} `json:"baseColorTexture"`
+		} `json:"pbrMetallicRoughness"`
+		EmissiveFactor []float32 `json:"emissiveFactor"`
+	} `json:"materials"`
+	Textures []struct {
+		Sampler *int `json:"sampler"`
+		Source  *int `json:"source"`
+	} `json:"textures"`
+	Samplers []struct {
+		WrapS *int32 `json:"wrapS"`
+		WrapT *int32 `json:"wrapT"`
+	} `json:"samplers"`
+	Images []struct {
+		URI        string `json:"uri"`
+		BufferView *int   `json:"bufferView"`
+	} `json:"images"`
+	Cameras []struct {
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		Perspective struct {
+			AspectRatio float32 `json:"aspectRatio"`
+			YFov        float32 `json:"yfov"`
+			ZNear       float32 `json:"znear"`
+			ZFar        float32 `json:"zfar"`
+		} `json:"perspective"`
+		Orthographic struct {
+			XMag  float32 `json:"xmag"`
+			YMag  float32 `json:"ymag"`
+			ZNear float32 `json:"znear"`
+			ZFar  float32 `json:"zfar"`
+		} `json:"orthographic"`
+	} `json:"cameras"`
+	Accessors []struct {
+		BufferView    *int   `json:"bufferView"`
+		ByteOffset    int    `json:"byteOffset"`
+		ComponentType uint32 `json:"componentType"`
+		Normalized    bool   `json:"normalized"`
+		Count         int    `json:"count"`
+		Type          string `json:"type"`
+		Sparse        *struct {
+		} `json:"sparse"`
+	} `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+	Extensions struct {
+		Lights *struct {
+			Lights []struct {
+				Name      string    `json:"name"`
+				Type      string    `json:"type"`
+				Color     []float32 `json:"color"`
+				Intensity *float32  `json:"intensity"`
+				Range     float32   `json:"range"`
+			} `json:"lights"`
+		} `json:"KHR_lights_punctual"`
+	} `json:"extensions"`
+}
+
+type gltfReader struct {
+	document gltfDocument
+	dir      string
+	buffers  [][]byte
+}
+
+func readGLTF(data []byte, dir string) (*Scene, error) {
+	reader := &gltfReader{dir: dir}
+	var binChunk []byte
+	if len(data) >= 12 && string(data[:4]) == "glTF" {
+		var err error
+		if data, binChunk, err = readGLB(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(data, &reader.document); err != nil {
+		return nil, err
+	}
+	document := &reader.document
+
+	for i, buffer := range document.Buffers {
+		var data []byte
+		var err error
+		if buffer.URI == "" {
+			if i != 0 || binChunk == nil {
+				return nil, fmt.Errorf("buffer %d has no data", i)
+			}
+			data = binChunk
+		} else if data, err = reader.readURI(buffer.URI); err != nil {
+			return nil, err
+		}
+		if len(data) < buffer.ByteLength {
+			return nil, fmt.Errorf("buffer %d has %d bytes, expected %d", i, len(data), buffer.ByteLength)
+		}
+		reader.buffers = append(reader.buffers, data)
+	}
+
+	scene := &Scene{}
+	for _, texture := range document.Textures {
+		sceneTexture := &sceneTexture{wrapS: gl.REPEAT, wrapT: gl.REPEAT}
+		if texture.Sampler != nil {
+			if *texture.Sampler < 0 || *texture.Sampler >= len(document.Samplers) {
+				return nil, fmt.Errorf("sampler %d out of range", *texture.Sampler)
+			}
+			sampler := document.Samplers[*texture.Sampler]
+			if sampler.WrapS != nil {
+				sceneTexture.wrapS = *sampler.WrapS
+			}
+			if sampler.WrapT != nil {
+				sceneTexture.wrapT = *sampler.WrapT
+			}
+		}
+		if texture.Source != nil {
+			img, err := reader.readImage(*texture.Source)
+			if err != nil {
+				return nil, err
+			}
+			sceneTexture.image = img
+		}
+		scene.textures = append(scene.textures, sceneTexture)
+	}
+
+	for _, material := range document.Materials {
+		pbr := &PBRMaterial{Name: material.Name, BaseColor: mgl32.Vec4{1, 1, 1, 1}, Metallic: 1, Roughness: 1}
+		if len(material.PBR.BaseColorFactor) == 4 {
+			copy(pbr.BaseColor[:], material.PBR.BaseColorFactor)
+		}
+		if material.PBR.MetallicFactor != nil {
+			pbr.Metallic = *material.PBR.MetallicFactor
+		}
+		if material.PBR.RoughnessFactor != nil {
+			pbr.Roughness = *material.PBR.RoughnessFactor
+		}
+		if len(material.EmissiveFactor) == 3 {
+			copy(pbr.Emissive[:], material.EmissiveFactor)
+		}
+		if texture := material.PBR.BaseColorTexture; texture != nil {
+			if texture.Index < 0 || texture.Index >= len(scene.textures) || scene.textures[texture.Index].image == nil {
+				return nil, fmt.Errorf("material %q: texture %d has no image", material.Name, texture.Index)
+			}
+			pbr.baseColorTexture = scene.textures[texture.Index]
+		}
+		scene.Materials = append(scene.Materials, pbr)
+	}
+
+	for m, mesh := range document.Meshes {
+		sceneMesh := &SceneMesh{Name: mesh.Name}
+		for p, primitive := range mesh.Primitives {
+			primitiveMesh, err := reader.readPrimitive(primitive.Attributes, primitive.Indices, primitive.Mode)
+			if err != nil {
+				return nil, fmt.Errorf("mesh %d primitive %d: %v", m, p, err)
+			}
+			var material *PBRMaterial
+			if primitive.Material != nil {
+				if *primitive.Material < 0 || *primitive.Material >= len(scene.Materials) {
+					return nil, fmt.Errorf("mesh %d primitive %d: material out of range", m, p)
+				}
+				material = scene.Materials[*primitive.Material]
+			}
+			sceneMesh.Meshes = append(sceneMesh.Meshes, primitiveMesh)
+			sceneMesh.Materials = append(sceneMesh.Materials, material)
+		}
+		scene.Meshes = append(scene.Meshes, sceneMesh)
+	}
+
+	roots := []int{}
+	if len(document.Scenes) > 0 {
+		index := 0
+		if document.Scene != nil {
+			index = *document.Scene
+		}
+		if index < 0 || index >= len(document.Scenes) {
+			return nil, fmt.Errorf("scene %d out of range", index)
+		}
+		roots = document.Scenes[index].Nodes
+	} else {
+		child := make([]bool, len(document.Nodes))
+		for _, node := range document.Nodes {
+			for _, c := range node.Children {
+				if c >= 0 && c < len(child) {
+					child[c] = true
+				}
+			}
+		}
+		for n := range document.Nodes {
+			if !child[n] {
+				roots = append(roots, n)
+			}
+		}
+	}
+	visited := make([]bool, len(document.Nodes))
+	for _, root := range roots {
+		node, err := reader.readNode(scene, root, mgl32.Ident4(), visited)
+		if err != nil {
+			return nil, err
+		}
+		scene.Nodes = append(scene.Nodes, node)
+	}
+	return scene, nil
+}
+
+//readGLB splits a binary glTF file into its JSON and BIN chunks.
+func readGLB(data []byte) (jsonChunk, binChunk []byte, err error) {
+	if version := binary.LittleEndian.Uint32(data[4:8]); version != 2 {
+		return nil, nil, fmt.Errorf("unsupported glb version %d", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:12]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("glb is truncated")
+	}
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		offset += 8
+		if offset+chunkLength > length {
+			return nil, nil, fmt.Errorf("glb chunk is truncated")
+		}
+		switch chunkType {
+		case "JSON":
+			jsonChunk = data[offset : offset+chunkLength]
+		case "BIN\x00":
+			binChunk = data[offset : offset+chunkLength]
+		}
+		offset += chunkLength
+	}
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("glb has no JSON chunk")
+	}
+	return jsonChunk, binChunk, nil
+}
+
+func (r *gltfReader) readURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.Index(uri, ",")
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("unsupported data uri")
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+	return ioutil.ReadFile(filepath.Join(r.dir, filepath.FromSlash(uri)))
+}
+
+func (r *gltfReader) readImage(index int) (image.Image, error) {
+	if index < 0 || index >= len(r.document.Images) {
+		return nil, fmt.Errorf("image %d out of range", index)
+	}
+	source := r.document.Images[index]
+	var data []byte
+	var err error
+	if source.BufferView != nil {
+		data, _, err = r.readBufferView(*source.BufferView)
+	} else {
+		data, err = r.readURI(source.URI)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("image %d: %v", index, err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image %d: %v", index, err)
+	}
+	return img, nil
+}
+
+func (r *gltfReader) readBufferView(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(r.document.BufferViews) {
+		return nil, 0, fmt.Errorf("buffer view %d out of range", index)
+	}
+	view := r.document.BufferViews[index]
+	if view.Buffer < 0 || view.Buffer >= len(r.buffers) {
+		return nil, 0, fmt.Errorf("buffer %d out of range", view.Buffer)
+	}
+	buffer := r.buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteStride < 0 {
+		return nil, 0, fmt.Errorf("buffer view %d has a negative offset, length or stride", index)
+	}
+	if view.ByteOffset > len(buffer) || view.ByteLength > len(buffer)-view.ByteOffset {
+		return nil, 0, fmt.Errorf("buffer view %d out of range", index)
+	}
+	return buffer[view.ByteOffset : view.ByteOffset+view.ByteLength], view.ByteStride, nil
+}
+
+//isInBufferView tells if count elements of elementSize bytes, every stride bytes from offset,
+//fit in length bytes. It's written to not overflow on the sizes of malformed files.
+func isInBufferView(length, offset, count, stride, elementSize int) bool {
+	if count == 0 {
+		return true
+	}
+	if offset > length || elementSize > length-offset {
+		return false
+	}
+	return count-1 <= (length-offset-elementSize)/stride
+}
+
+//readAccessor returns the elements of an accessor as floats, components per element given by
+//its type. Normalized integers are mapped to [0, 1] or [-1, 1].
+func (r *gltfReader) readAccessor(index int) ([]float32, int, error) {
+	if index < 0 || index >= len(r.document.Accessors) {
+		return nil, 0, fmt.Errorf("accessor %d out of range", index)
+	}
+	accessor := r.document.Accessors[index]
+	if accessor.Sparse != nil {
+		return nil, 0, fmt.Errorf("accessor %d: sparse accessors are not supported", index)
+	}
+	components, ok := map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16}[accessor.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unknown type %q", index, accessor.Type)
+	}
+	componentSize, ok := map[uint32]int{gl.BYTE: 1, gl.UNSIGNED_BYTE: 1, gl.SHORT: 2, gl.UNSIGNED_SHORT: 2, gl.UNSIGNED_INT: 4, gl.FLOAT: 4}[accessor.ComponentType]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unknown component type %d", index, accessor.ComponentType)
+	}
+	if accessor.ByteOffset < 0 || accessor.Count < 0 {
+		return nil, 0, fmt.Errorf("accessor %d has a negative offset or count", index)
+	}
+	if accessor.BufferView == nil {
+		// accessors without a buffer view are all zeros
+		return make([]float32, accessor.Count*components), components, nil
+	}
+	data, stride, err := r.readBufferView(*accessor.BufferView)
+	if err != nil {
+		return nil, 0, fmt.Errorf("accessor %d: %v", index, err)
+	}
+	if stride == 0 {
+		stride = components * componentSize
+	}
+	if !isInBufferView(len(data), accessor.ByteOffset, accessor.Count, stride, components*componentSize) {
+		return nil, 0, fmt.Errorf("accessor %d out of range of its buffer view", index)
+	}
+	values := make([]float32, accessor.Count*components)
+	for e := 0; e < accessor.Count; e++ {
+		for c := 0; c < components; c++ {
+			at := data[accessor.ByteOffset+e*stride+c*componentSize:]
+			var value float32
+			switch accessor.ComponentType {
+			case gl.BYTE:
+				value = float32(int8(at[0]))
+				if accessor.Normalized {
+					value = float32(math.Max(float64(value)/127, -1))
+				}
+			case gl.UNSIGNED_BYTE:
+				value = float32(at[0])
+				if accessor.Normalized {
+					value /= 255
+				}
+			case gl.SHORT:
+				value = float32(int16(binary.LittleEndian.Uint16(at)))
+				if accessor.Normalized {
+					value = float32(math.Max(float64(value)/32767, -1))
+				}
+			case gl.UNSIGNED_SHORT:
+				value = float32(binary.LittleEndian.Uint16(at))
+				if accessor.Normalized {
+					value /= 65535
+				}
+			case gl.UNSIGNED_INT:
+				value = float32(binary.LittleEndian.Uint32(at))
+			case gl.FLOAT:
+				value = math.Float32frombits(binary.LittleEndian.Uint32(at))
+			}
+			values[e*components+c] = value
+		}
+	}
+	return values, components, nil
+}
+
+//readIndices reads an index accessor without going through floats, which can't hold every
+//32 bit index.
+func (r *gltfReader) readIndices(index int) ([]uint32, error) {
+	if index < 0 || index >= len(r.document.Accessors) {
+		return nil, fmt.Errorf("accessor %d out of range", index)
+	}
+	accessor := r.document.Accessors[index]
+	if accessor.Type != "SCALAR" || accessor.BufferView == nil || accessor.Sparse != nil {
+		return nil, fmt.Errorf("accessor %d: unsupported index accessor", index)
+	}
+	componentSize, ok := map[uint32]int{gl.UNSIGNED_BYTE: 1, gl.UNSIGNED_SHORT: 2, gl.UNSIGNED_INT: 4}[accessor.ComponentType]
+	if !ok {
+		return nil, fmt.Errorf("accessor %d: unsupported index type %d", index, accessor.ComponentType)
+	}
+	if accessor.ByteOffset < 0 || accessor.Count < 0 {
+		return nil, fmt.Errorf("accessor %d has a negative offset or count", index)
+	}
+	data, stride, err := r.readBufferView(*accessor.BufferView)
+	if err != nil {
+		return nil, fmt.Errorf("accessor %d: %v", index, err)
+	}
+	if stride == 0 {
+		stride = componentSize
+	}
+	if !isInBufferView(len(data), accessor.ByteOffset, accessor.Count, stride, componentSize) {
+		return nil, fmt.Errorf("accessor %d out of range of its buffer view", index)
+	}
+	indices := make([]uint32, accessor.Count)
+	for i := range indices {
+		at := data[accessor.ByteOffset+i*stride:]
+		switch componentSize {
+		case 1:
+			indices[i] = uint32(at[0])
+		case 2:
+			indices[i] = uint32(binary.LittleEndian.Uint16(at))
+		case 4:
+			indices[i] = binary.LittleEndian.Uint32(at)
+		}
+	}
+	return indices, nil
+}
+
+//readPrimitive builds an indexed triangle list from a primitive. Primitives without normals get
+//flat ones, as the specification asks.
+func (r *gltfReader) readPrimitive(attributes map[string]int, indicesAccessor *int, mode *int) (*Mesh, error) {
+	positionAccessor, ok := attributes["POSITION"]
+	if !ok {
+		return nil, fmt.Errorf("primitive has no POSITION")
+	}
+	positionValues, components, err := r.readAccessor(positionAccessor)
+	if err != nil {
+		return nil, err
+	}
+	if components != 3 {
+		return nil, fmt.Errorf("POSITION is not VEC3")
+	}
+	count := len(positionValues) / 3
+	mesh := &Mesh{Positions: make([]mgl32.Vec3, count)}
+	for i := range mesh.Positions {
+		copy(mesh.Positions[i][:], positionValues[3*i:])
+	}
+	if normalAccessor, ok := attributes["NORMAL"]; ok {
+		values, components, err := r.readAccessor(normalAccessor)
+		if err != nil {
+			return nil, err
+		}
+		if components != 3 || len(values) != 3*count {
+			return nil, fmt.Errorf("NORMAL does not match POSITION")
+		}
+		mesh.Normals = make([]mgl32.Vec3, count)
+		for i := range mesh.Normals {
+			copy(mesh.Normals[i][:], values[3*i:])
+		}
+	}
+	if uvAccessor, ok := attributes["TEXCOORD_0"]; ok {
+		// glTF texture coordinates already start at the top of the image
+		values, components, err := r.readAccessor(uvAccessor)
+		if err != nil {
+			return nil, err
+		}
+		if components != 2 || len(values) != 2*count {
+			return nil, fmt.Errorf("TEXCOORD_0 does not match POSITION")
+		}
+		mesh.UVs = make([]mgl32.Vec2, count)
+		for i := range mesh.UVs {
+			copy(mesh.UVs[i][:], values[2*i:])
+		}
+	}
+
+	var indices []uint32
+	if indicesAccessor != nil {
+		if indices, err = r.readIndices(*indicesAccessor); err != nil {
+			return nil, err
+		}
+		for _, index := range indices {
+			if int(index) >= count {
+				return nil, fmt.Errorf("index %d out of range", index)
+			}
+		}
+	} else {
+		indices = make([]uint32, count)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	primitiveMode := gl.TRIANGLES
+	if mode != nil {
+		primitiveMode = *mode
+	}
+	switch primitiveMode {
+	case gl.TRIANGLES:
+		mesh.Indices = indices[:len(indices)/3*3]
+	case gl.TRIANGLE_STRIP:
+		for i := 0; i+2 < len(indices); i++ {
+			if i%2 == 0 {
+				mesh.Indices = append(mesh.Indices, indices[i], indices[i+1], indices[i+2])
+			} else {
+				mesh.Indices = append(mesh.Indices, indices[i+1], indices[i], indices[i+2])
+			}
+		}
+	case gl.TRIANGLE_FAN:
+		for i := 1; i+1 < len(indices); i++ {
+			mesh.Indices = append(mesh.Indices, indices[0], indices[i], indices[i+1])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported primitive mode %d", primitiveMode)
+	}
+
+	if mesh.Normals == nil {
+		flat := &Mesh{}
+		for _, index := range mesh.Indices {
+			flat.Positions = append(flat.Positions, mesh.Positions[index])
+			if mesh.UVs != nil {
+				flat.UVs = append(flat.UVs, mesh.UVs[index])
+			}
+			flat.Indices = append(flat.Indices, uint32(len(flat.Indices)))
+		}
+		flat.Normals = GetFlatNormals3(flat.Positions)
+		mesh = flat
+	}
+	return mesh, nil
+}
+
+func (r *gltfReader) readNode(scene *Scene, index int, parent mgl32.Mat4, visited []bool) (*SceneNode, error) {
+	document := &r.document
+	if index < 0 || index >= len(document.Nodes) {
+		return nil, fmt.Errorf("node %d out of range", index)
+	}
+	if visited[index] {
+		return nil, fmt.Errorf("node %d is used twice", index)
+	}
+	visited[index] = true
+	source := document.Nodes[index]
+
+	node := &SceneNode{Name: source.Name, Local: mgl32.Ident4()}
+	if len(source.Matrix) == 16 {
+		copy(node.Local[:], source.Matrix)
+	} else {
+		translation, rotation, scale := mgl32.Ident4(), mgl32.Ident4(), mgl32.Ident4()
+		if len(source.Translation) == 3 {
+			translation = mgl32.Translate3D(source.Translation[0], source.Translation[1], source.Translation[2])
+		}
+		if len(source.Rotation) == 4 {
+			rotation = mgl32.Quat{W: source.Rotation[3], V: mgl32.Vec3{source.Rotation[0], source.Rotation[1], source.Rotation[2]}}.Normalize().Mat4()
+		}
+		if len(source.Scale) == 3 {
+			scale = mgl32.Scale3D(source.Scale[0], source.Scale[1], source.Scale[2])
+		}
+		node.Local = translation.Mul4(rotation).Mul4(scale)
+	}
+	node.World = parent.Mul4(node.Local)
+	position := node.World.Col(3).Vec3()
+
+	if source.Mesh != nil {
+		if *source.Mesh < 0 || *source.Mesh >= len(scene.Meshes) {
+			return nil, fmt.Errorf("node %d: mesh out of range", index)
+		}
+		node.Mesh = scene.Meshes[*source.Mesh]
+	}
+	if source.Camera != nil {
+		if *source.Camera < 0 || *source.Camera >= len(document.Cameras) {
+			return nil, fmt.Errorf("node %d: camera out of range", index)
+		}
+		camera := document.Cameras[*source.Camera]
+		sceneCamera := &SceneCamera{Name: camera.Name, View: node.World.Inv(), Position: position}
+		if camera.Type == "orthographic" {
+			sceneCamera.XMag, sceneCamera.YMag = camera.Orthographic.XMag, camera.Orthographic.YMag
+			sceneCamera.ZNear, sceneCamera.ZFar = camera.Orthographic.ZNear, camera.Orthographic.ZFar
+		} else {
+			sceneCamera.Perspective = true
+			sceneCamera.YFov, sceneCamera.AspectRatio = camera.Perspective.YFov, camera.Perspective.AspectRatio
+			sceneCamera.ZNear, sceneCamera.ZFar = camera.Perspective.ZNear, camera.Perspective.ZFar
+		}
+		scene.Cameras = append(scene.Cameras, sceneCamera)
+	}
+	if source.Extensions.Lights != nil && document.Extensions.Lights != nil {
+		lights := document.Extensions.Lights.Lights
+		if source.Extensions.Lights.Light < 0 || source.Extensions.Lights.Light >= len(lights) {
+			return nil, fmt.Errorf("node %d: light out of range", index)
+		}
+		light := lights[source.Extensions.Lights.Light]
+		if light.Type == "point" {
+			pointLight := &ScenePointLight{Name: light.Name, Color: mgl32.Vec3{1, 1, 1}, Intensity: 1, Range: light.Range, Position: position}
+			if len(light.Color) == 3 {
+				copy(pointLight.Color[:], light.Color)
+			}
+			if light.Intensity != nil {
+				pointLight.Intensity = *light.Intensity
+			}
+			scene.Lights = append(scene.Lights, pointLight)
+		}
+	}
+
+	for _, child := range source.Children {
+		childNode, err := r.readNode(scene, child, node.World, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
@@ -0,0 +1,163 @@
+package ge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//getTriangleGLTF returns a document with a triangle facing +z, moved 2 along z by its node, and
+//the buffer it points to: three float positions followed by three unsigned short indices.
+func getTriangleGLTF() (map[string]interface{}, []byte) {
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	binary.Write(&buffer, binary.LittleEndian, []uint16{0, 1, 2, 0})
+	var document map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"asset": {"version": "2.0"},
+		"scene": 0,
+		"scenes": [{"nodes": [0]}],
+		"nodes": [{"mesh": 0, "translation": [0, 0, 2]}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1, "material": 0}]}],
+		"materials": [{"pbrMetallicRoughness": {"baseColorFactor": [1, 0, 0, 1]}}],
+		"accessors": [
+			{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+			{"bufferView": 1, "componentType": 5123, "count": 3, "type": "SCALAR"}
+		],
+		"bufferViews": [
+			{"buffer": 0, "byteOffset": 0, "byteLength": 36},
+			{"buffer": 0, "byteOffset": 36, "byteLength": 6}
+		],
+		"buffers": [{"byteLength": 44}]
+	}`), &document)
+	return document, buffer.Bytes()
+}
+
+func getGLTFObject(document map[string]interface{}, key string, index int) map[string]interface{} {
+	return document[key].([]interface{})[index].(map[string]interface{})
+}
+
+//writeGLTF saves document as a .gltf file, with the buffer embedded as a data uri, or as a
+//.glb file, with the buffer in its BIN chunk.
+func writeGLTF(t *testing.T, format string, document map[string]interface{}, buffer []byte) string {
+	if format == "gltf" {
+		getGLTFObject(document, "buffers", 0)["uri"] = "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buffer)
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format == "glb" {
+		// chunks are padded to 4 bytes, the JSON one with spaces
+		for len(data)%4 != 0 {
+			data = append(data, ' ')
+		}
+		var glb bytes.Buffer
+		binary.Write(&glb, binary.LittleEndian, []uint32{0x46546c67, 2, uint32(12 + 8 + len(data) + 8 + len(buffer))})
+		binary.Write(&glb, binary.LittleEndian, []uint32{uint32(len(data)), 0x4e4f534a})
+		glb.Write(data)
+		binary.Write(&glb, binary.LittleEndian, []uint32{uint32(len(buffer)), 0x004e4942})
+		glb.Write(buffer)
+		data = glb.Bytes()
+	}
+	file := filepath.Join(t.TempDir(), "triangle."+format)
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadGLTF(t *testing.T) {
+	for _, format := range []string{"gltf", "glb"} {
+		document, buffer := getTriangleGLTF()
+		scene, err := LoadGLTF(writeGLTF(t, format, document, buffer))
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(scene.Nodes) != 1 || scene.Nodes[0].Mesh == nil || len(scene.Nodes[0].Mesh.Meshes) != 1 {
+			t.Fatalf("%s: expected one node with one primitive", format)
+		}
+		node := scene.Nodes[0]
+		if node.World.Col(3) != (mgl32.Vec4{0, 0, 2, 1}) {
+			t.Errorf("%s: node is at %v, want 0, 0, 2", format, node.World.Col(3))
+		}
+		mesh := node.Mesh.Meshes[0]
+		if len(mesh.Indices) != 3 {
+			t.Fatalf("%s: %d indices, want 3", format, len(mesh.Indices))
+		}
+		want := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+		for i, index := range mesh.Indices {
+			if mesh.Positions[index] != want[i] {
+				t.Errorf("%s: corner %d is %v, want %v", format, i, mesh.Positions[index], want[i])
+			}
+			// primitives without normals get flat ones
+			if mesh.Normals[index] != (mgl32.Vec3{0, 0, 1}) {
+				t.Errorf("%s: normal %d is %v, want 0, 0, 1", format, i, mesh.Normals[index])
+			}
+		}
+		if material := node.Mesh.Materials[0]; material == nil || material.BaseColor != (mgl32.Vec4{1, 0, 0, 1}) {
+			t.Errorf("%s: the primitive lost its material", format)
+		}
+	}
+}
+
+func TestLoadGLTFRejectsMalformedFiles(t *testing.T) {
+	cases := []struct {
+		name string
+		edit func(document map[string]interface{})
+	}{
+		{"negative buffer view offset", func(d map[string]interface{}) { getGLTFObject(d, "bufferViews", 1)["byteOffset"] = -4 }},
+		{"negative buffer view length", func(d map[string]interface{}) { getGLTFObject(d, "bufferViews", 0)["byteLength"] = -8 }},
+		{"negative buffer view stride", func(d map[string]interface{}) { getGLTFObject(d, "bufferViews", 0)["byteStride"] = -12 }},
+		{"buffer view past its buffer", func(d map[string]interface{}) { getGLTFObject(d, "bufferViews", 1)["byteLength"] = 100 }},
+		{"negative accessor offset", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 0)["byteOffset"] = -8 }},
+		{"negative accessor count", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 0)["count"] = -1 }},
+		{"huge accessor count", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 0)["count"] = 1 << 40 }},
+		{"accessor past its buffer view", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 0)["byteOffset"] = 4 }},
+		{"negative index offset", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 1)["byteOffset"] = -4 }},
+		{"negative index count", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 1)["count"] = -3 }},
+		{"huge index count", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 1)["count"] = 1 << 40 }},
+		{"negative buffer view", func(d map[string]interface{}) { getGLTFObject(d, "accessors", 0)["bufferView"] = -1 }},
+		{"negative material", func(d map[string]interface{}) {
+			getGLTFObject(getGLTFObject(d, "meshes", 0), "primitives", 0)["material"] = -1
+		}},
+		{"negative mesh", func(d map[string]interface{}) { getGLTFObject(d, "nodes", 0)["mesh"] = -1 }},
+		{"negative scene", func(d map[string]interface{}) { d["scene"] = -1 }},
+		{"negative sampler", func(d map[string]interface{}) {
+			d["textures"] = []interface{}{map[string]interface{}{"sampler": -1}}
+		}},
+	}
+	for _, c := range cases {
+		for _, format := range []string{"gltf", "glb"} {
+			document, buffer := getTriangleGLTF()
+			c.edit(document)
+			if _, err := LoadGLTF(writeGLTF(t, format, document, buffer)); err == nil {
+				t.Errorf("%s %s: loaded without an error", format, c.name)
+			}
+		}
+	}
+}
+
+func TestLoadGLBRejectsTruncatedFiles(t *testing.T) {
+	document, buffer := getTriangleGLTF()
+	data, err := ioutil.ReadFile(writeGLTF(t, "glb", document, buffer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, length := range []int{12, 20, len(data) - 4} {
+		file := filepath.Join(t.TempDir(), "truncated.glb")
+		// the header still claims the whole file
+		if err := ioutil.WriteFile(file, data[:length], 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadGLTF(file); err == nil {
+			t.Errorf("glb cut at %d bytes loaded without an error", length)
+		}
+	}
+}
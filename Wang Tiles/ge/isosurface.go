@@ -0,0 +1,363 @@
+package ge
+
+import (
+	"math"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//ScalarField is a function of space whose surface is where it crosses 0, negative inside and
+//positive outside, like a signed distance. Noise volumes and metaballs are fields too, shifted
+//by the value their surface should be at.
+type ScalarField func(p mgl32.Vec3) float32
+
+//SphereField is the signed distance to a sphere.
+func SphereField(center mgl32.Vec3, r float32) ScalarField {
+	return func(p mgl32.Vec3) float32 {
+		return p.Sub(center).Len() - r
+	}
+}
+
+//BoxField is the signed distance to the box b.
+func BoxField(b AABB) ScalarField {
+	center, half := b.Center(), b.Size().Mul(0.5)
+	return func(p mgl32.Vec3) float32 {
+		var outside mgl32.Vec3
+		inside := float32(-math.MaxFloat32)
+		for axis := range p {
+			d := math32.Abs(p[axis]-center[axis]) - half[axis]
+			outside[axis] = math32.Max(d, 0)
+			inside = math32.Max(inside, d)
+		}
+		return outside.Len() + math32.Min(inside, 0)
+	}
+}
+
+//MetaballField blends balls of the given centers and radii into blobs. Each ball adds
+//(r / distance)², the surface is where the sum reaches threshold, so with a threshold of 1 a
+//lonely ball keeps its radius and close balls melt together.
+func MetaballField(centers []mgl32.Vec3, radii []float32, threshold float32) ScalarField {
+	return func(p mgl32.Vec3) float32 {
+		sum := float32(0)
+		for i, center := range centers {
+			d := p.Sub(center).LenSqr()
+			if d == 0 {
+				return -threshold
+			}
+			sum += radii[i] * radii[i] / d
+		}
+		return threshold - sum
+	}
+}
+
+//UnionField is the inside of any of fields.
+func UnionField(fields ...ScalarField) ScalarField {
+	return func(p mgl32.Vec3) float32 {
+		value := float32(math.MaxFloat32)
+		for _, field := range fields {
+			value = math32.Min(value, field(p))
+		}
+		return value
+	}
+}
+
+//IntersectionField is the inside of all of fields.
+func IntersectionField(fields ...ScalarField) ScalarField {
+	return func(p mgl32.Vec3) float32 {
+		value := float32(-math.MaxFloat32)
+		for _, field := range fields {
+			value = math32.Max(value, field(p))
+		}
+		return value
+	}
+}
+
+//SubtractionField is the inside of field with the inside of hole carved out, for caves.
+func SubtractionField(field ScalarField, hole ScalarField) ScalarField {
+	return func(p mgl32.Vec3) float32 {
+		return math32.Max(field(p), -hole(p))
+	}
+}
+
+//Gradient estimates the gradient of the field at p by central differences h apart, it points
+//out of the surface.
+func (f ScalarField) Gradient(p mgl32.Vec3, h float32) mgl32.Vec3 {
+	var gradient mgl32.Vec3
+	for axis := range gradient {
+		var offset mgl32.Vec3
+		offset[axis] = h
+		gradient[axis] = (f(p.Add(offset)) - f(p.Sub(offset))) / (2 * h)
+	}
+	return gradient
+}
+
+//normal is the unit gradient at p, or zero where the field is flat.
+func (f ScalarField) normal(p mgl32.Vec3, h float32) mgl32.Vec3 {
+	if gradient := f.Gradient(p, h); gradient.Len() > 0 {
+		return gradient.Normalize()
+	}
+	return mgl32.Vec3{}
+}
+
+//isoGrid is field sampled on the corners of cubes of side cellSize filling bounds.
+type isoGrid struct {
+	field    ScalarField
+	min      mgl32.Vec3
+	size     mgl32.Vec3
+	cellSize float32
+	cells    [3]int
+	values   []float32
+}
+
+func newIsoGrid(field ScalarField, bounds AABB, cellSize float32) *isoGrid {
+	g := &isoGrid{field: field, min: bounds.Min, size: bounds.Size(), cellSize: cellSize}
+	for axis := range g.cells {
+		g.cells[axis] = maxInt(1, int(math32.Ceil(g.size[axis]/cellSize)))
+	}
+	g.values = make([]float32, (g.cells[0]+1)*(g.cells[1]+1)*(g.cells[2]+1))
+	for k := 0; k <= g.cells[2]; k++ {
+		for j := 0; j <= g.cells[1]; j++ {
+			for i := 0; i <= g.cells[0]; i++ {
+				g.values[g.index(i, j, k)] = field(g.point(i, j, k))
+			}
+		}
+	}
+	return g
+}
+
+func (g *isoGrid) index(i, j, k int) int {
+	return i + (g.cells[0]+1)*(j+(g.cells[1]+1)*k)
+}
+
+func (g *isoGrid) point(i, j, k int) mgl32.Vec3 {
+	return g.min.Add(mgl32.Vec3{float32(i), float32(j), float32(k)}.Mul(g.cellSize))
+}
+
+//crossing returns where the field crosses 0 on the edge from corner i, j, k along axis, false
+//when it doesn't.
+func (g *isoGrid) crossing(i, j, k, axis int) (mgl32.Vec3, bool) {
+	next := [3]int{i, j, k}
+	next[axis]++
+	a, b := g.values[g.index(i, j, k)], g.values[g.index(next[0], next[1], next[2])]
+	if (a < 0) == (b < 0) {
+		return mgl32.Vec3{}, false
+	}
+	t := a / (a - b)
+	pa, pb := g.point(i, j, k), g.point(next[0], next[1], next[2])
+	return pa.Add(pb.Sub(pa).Mul(t)), true
+}
+
+//addVertex adds p to mesh with the normal of the field and uvs spread over the bounds along x
+//and z, like the ones of a Terrain.
+func (g *isoGrid) addVertex(mesh *Mesh, p mgl32.Vec3) uint32 {
+	mesh.Positions = append(mesh.Positions, p)
+	mesh.Normals = append(mesh.Normals, g.field.normal(p, g.cellSize/4))
+	uv := mgl32.Vec2{}
+	if g.size.X() > 0 && g.size.Z() > 0 {
+		uv = mgl32.Vec2{(p.X() - g.min.X()) / g.size.X(), (p.Z() - g.min.Z()) / g.size.Z()}
+	}
+	mesh.UVs = append(mesh.UVs, uv)
+	return uint32(len(mesh.Positions) - 1)
+}
+
+//the faces of a cube by their corners counter clockwise seen from outside, corner c is at
+//(c&1, c>>1&1, c>>2&1)
+var cubeFaces = [6][4]int{
+	{0, 4, 6, 2}, {1, 3, 7, 5},
+	{0, 1, 5, 4}, {2, 6, 7, 3},
+	{0, 2, 3, 1}, {4, 5, 7, 6},
+}
+
+//cubeEdgeAxes is the axis of the edge between two corners of a cube by the xor of the corners.
+var cubeEdgeAxes = [5]int{1: 0, 2: 1, 4: 2}
+
+//MarchingCubes extracts the surface of field inside bounds as a mesh of triangles with cubes of
+//side cellSize, vertices on the edges of the cubes are shared so the mesh is smooth. The
+//polygons of every cube are traced across its faces instead of looked up in the usual tables,
+//the faces with two diagonal corners inside are split by the asymptotic decider, so the surface
+//is closed where it doesn't leave bounds. Normals are the gradient of the field and uvs spread
+//over the bounds along x and z.
+func MarchingCubes(field ScalarField, bounds AABB, cellSize float32) *Mesh {
+	g := newIsoGrid(field, bounds, cellSize)
+	mesh := NewMesh(nil, nil, nil, nil)
+	// vertices by the grid corner their edge starts at and its axis
+	vertices := map[[2]int]uint32{}
+	vertex := func(base [3]int, a, b int) uint32 {
+		if a > b {
+			a, b = b, a
+		}
+		axis := cubeEdgeAxes[a^b]
+		i, j, k := base[0]+a&1, base[1]+a>>1&1, base[2]+a>>2&1
+		key := [2]int{g.index(i, j, k), axis}
+		if index, ok := vertices[key]; ok {
+			return index
+		}
+		p, _ := g.crossing(i, j, k, axis)
+		vertices[key] = g.addVertex(mesh, p)
+		return vertices[key]
+	}
+
+	for k := 0; k < g.cells[2]; k++ {
+		for j := 0; j < g.cells[1]; j++ {
+			for i := 0; i < g.cells[0]; i++ {
+				var values [8]float32
+				inside := 0
+				for c := range values {
+					values[c] = g.values[g.index(i+c&1, j+c>>1&1, k+c>>2&1)]
+					if values[c] < 0 {
+						inside++
+					}
+				}
+				if inside == 0 || inside == 8 {
+					continue
+				}
+				base := [3]int{i, j, k}
+				// every crossed edge is on two faces, on each the surface goes from the edge it
+				// enters the inside through to the one it leaves through, so they close into
+				// loops counter clockwise seen from outside
+				next := map[[2]int][2]int{}
+				crossings := [][2]int{}
+				for _, face := range cubeFaces {
+					var entries, exits [][2]int
+					for c := range face {
+						a, b := face[c], face[(c+1)%4]
+						switch {
+						case values[a] >= 0 && values[b] < 0:
+							entries = append(entries, getEdgeKey(a, b))
+						case values[a] < 0 && values[b] >= 0:
+							exits = append(exits, getEdgeKey(a, b))
+						}
+					}
+					switch len(entries) {
+					case 1:
+						next[entries[0]] = exits[0]
+						crossings = append(crossings, entries[0])
+					case 2:
+						v0, v1, v2, v3 := values[face[0]], values[face[1]], values[face[2]], values[face[3]]
+						saddle := (v0 + v1 + v2 + v3) / 4
+						if denominator := v0 + v2 - v1 - v3; denominator != 0 {
+							saddle = (v0*v2 - v1*v3) / denominator
+						}
+						// the surface goes around the corners of face[0] and face[2], or of
+						// face[1] and face[3], whichever aren't joined through the middle
+						first, second := exits[0], exits[1]
+						if joined := saddle < 0; joined != (v0 < 0) {
+							first, second = second, first
+						}
+						next[entries[0]], next[entries[1]] = first, second
+						crossings = append(crossings, entries...)
+					}
+				}
+				visited := map[[2]int]bool{}
+				for _, start := range crossings {
+					loop := []uint32{}
+					for current := start; !visited[current]; current = next[current] {
+						visited[current] = true
+						loop = append(loop, vertex(base, current[0], current[1]))
+					}
+					for t := 1; t+1 < len(loop); t++ {
+						mesh.appendWoundTriangle(loop[0], loop[t], loop[t+1])
+					}
+				}
+			}
+		}
+	}
+	return mesh
+}
+
+//DualContouring extracts the surface of field inside bounds like MarchingCubes, but with one
+//vertex per cube the surface goes through, placed where the tangent planes of the surface at
+//the crossed edges meet. It keeps the sharp edges and corners of fields like BoxField that
+//MarchingCubes rounds off, with quads joining the cubes around every crossed edge.
+func DualContouring(field ScalarField, bounds AABB, cellSize float32) *Mesh {
+	g := newIsoGrid(field, bounds, cellSize)
+	mesh := NewMesh(nil, nil, nil, nil)
+	cells := map[[3]int]uint32{}
+	cellVertex := func(i, j, k int) (uint32, bool) {
+		if i < 0 || j < 0 || k < 0 || i >= g.cells[0] || j >= g.cells[1] || k >= g.cells[2] {
+			return 0, false
+		}
+		if index, ok := cells[[3]int{i, j, k}]; ok {
+			return index, true
+		}
+		// least squares point of the planes at the crossings, pulled slightly towards their
+		// average so flat and ill defined cells stay put
+		var ata mgl32.Mat3
+		var atb, mass mgl32.Vec3
+		count := float32(0)
+		for c := 0; c < 8; c++ {
+			for axis := 0; axis < 3; axis++ {
+				if c&(1<<uint(axis)) != 0 {
+					continue
+				}
+				p, ok := g.crossing(i+c&1, j+c>>1&1, k+c>>2&1, axis)
+				if !ok {
+					continue
+				}
+				n := field.normal(p, g.cellSize/4)
+				for row := 0; row < 3; row++ {
+					for column := 0; column < 3; column++ {
+						ata[column*3+row] += n[row] * n[column]
+					}
+				}
+				atb = atb.Add(n.Mul(n.Dot(p)))
+				mass = mass.Add(p)
+				count++
+			}
+		}
+		if count == 0 {
+			return 0, false
+		}
+		mass = mass.Mul(1 / count)
+		const bias = 0.1
+		for d := 0; d < 3; d++ {
+			ata[d*4] += bias
+		}
+		p := ata.Inv().Mul3x1(atb.Add(mass.Mul(bias)))
+		min, max := g.point(i, j, k), g.point(i+1, j+1, k+1)
+		for axis := range p {
+			p[axis] = math32.Max(min[axis], math32.Min(max[axis], p[axis]))
+		}
+		cells[[3]int{i, j, k}] = g.addVertex(mesh, p)
+		return cells[[3]int{i, j, k}], true
+	}
+
+	for k := 0; k <= g.cells[2]; k++ {
+		for j := 0; j <= g.cells[1]; j++ {
+			for i := 0; i <= g.cells[0]; i++ {
+				for axis := 0; axis < 3; axis++ {
+					corner := [3]int{i, j, k}
+					if corner[axis] == g.cells[axis] {
+						continue
+					}
+					if _, ok := g.crossing(i, j, k, axis); !ok {
+						continue
+					}
+					// the four cubes around the edge, in order around it
+					u, v := (axis+1)%3, (axis+2)%3
+					quad := [4]uint32{}
+					complete := true
+					for q, offset := range [4][2]int{{-1, -1}, {0, -1}, {0, 0}, {-1, 0}} {
+						cell := corner
+						cell[u] += offset[0]
+						cell[v] += offset[1]
+						index, ok := cellVertex(cell[0], cell[1], cell[2])
+						complete = complete && ok
+						quad[q] = index
+					}
+					if !complete {
+						continue
+					}
+					// the quad is counter clockwise seen from the end of the edge along axis,
+					// turn it around when the outside is at the start
+					if g.values[g.index(i, j, k)] >= 0 {
+						quad[1], quad[3] = quad[3], quad[1]
+					}
+					mesh.Indices = append(mesh.Indices, quad[0], quad[1], quad[2], quad[0], quad[2], quad[3])
+				}
+			}
+		}
+	}
+	return mesh
+}
@@ -0,0 +1,64 @@
+package ge
+
+import (
+	"testing"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestIsosurfacesAreClosedAndOutward(t *testing.T) {
+	bounds := AABB{mgl32.Vec3{-2, -2, -2}, mgl32.Vec3{2, 2, 2}}
+	fields := []struct {
+		name   string
+		field  ScalarField
+		volume float32
+	}{
+		{"sphere", SphereField(mgl32.Vec3{.1, 0, 0}, 1.3), 4. / 3 * math32.Pi * 1.3 * 1.3 * 1.3},
+		{"box", BoxField(AABB{mgl32.Vec3{-1, -.5, -.7}, mgl32.Vec3{1.03, .5, .7}}), 2.03 * 1 * 1.4},
+		{"metaballs", MetaballField([]mgl32.Vec3{{-.6, 0, 0}, {.6, 0, 0}, {0, .5, .3}}, []float32{.6, .5, .4}, 1), 0},
+	}
+	extractors := []struct {
+		name    string
+		extract func(ScalarField, AABB, float32) *Mesh
+	}{
+		{"MarchingCubes", MarchingCubes},
+		{"DualContouring", DualContouring},
+	}
+	for _, field := range fields {
+		for _, extractor := range extractors {
+			mesh := extractor.extract(field.field, bounds, .1)
+			name := extractor.name + " " + field.name
+			if len(mesh.Indices) == 0 {
+				t.Fatalf("%s is empty", name)
+			}
+			if open := countOpenEdges(mesh); open != 0 {
+				t.Errorf("%s has %d open edges", name, open)
+			}
+			volume := getSignedVolume(mesh)
+			if volume <= 0 {
+				t.Errorf("%s faces inwards, signed volume %v", name, volume)
+			}
+			if field.volume > 0 && math32.Abs(volume-field.volume) > 0.05*field.volume {
+				t.Errorf("%s has volume %v, want about %v", name, volume, field.volume)
+			}
+		}
+	}
+}
+
+func TestMarchingCubesSkipsCornersOnTheSurface(t *testing.T) {
+	// the grid has corners right on the sphere, like (0, 1, 0)
+	mesh := MarchingCubes(SphereField(mgl32.Vec3{}, 1), AABB{mgl32.Vec3{-2, -2, -2}, mgl32.Vec3{2, 2, 2}}, .1)
+	edges := map[[2]mgl32.Vec3]int{}
+	for i := 0; i+2 < len(mesh.Indices); i += 3 {
+		for k := 0; k < 3; k++ {
+			edge := [2]mgl32.Vec3{mesh.Positions[mesh.Indices[i+k]], mesh.Positions[mesh.Indices[i+(k+1)%3]]}
+			if edges[edge]++; edges[edge] > 1 {
+				t.Fatalf("edge %v is used twice the same way", edge)
+			}
+		}
+	}
+	if open := countOpenEdges(mesh); open != 0 {
+		t.Errorf("%d open edges", open)
+	}
+}
@@ -0,0 +1,88 @@
+package ge
+
+import (
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//LOD is a chain of levels of detail of a mesh, from the original to the simplest, and the
+//bounding sphere used to pick one by how big it looks on screen.
+type LOD struct {
+	Levels []*Mesh
+	// ScreenSizes[i] is the smallest fraction of the screen height the bounding sphere has to
+	// cover to draw Levels[i], the last level is drawn below all of them
+	ScreenSizes []float32
+	Bounds      BoundingSphere
+}
+
+//NewLOD simplifies mesh to every count of triangles, from the biggest to the smallest, and
+//keeps mesh as the first level. Each level is drawn while the sphere covers half the screen
+//height times the square root of its share of the triangles of mesh, so the triangles keep
+//about the same size on screen as the mesh goes away.
+func NewLOD(mesh *Mesh, triangles ...int) *LOD {
+	lod := &LOD{Levels: []*Mesh{mesh}, Bounds: mesh.BoundingSphere()}
+	total := len(mesh.Indices) / 3
+	for _, count := range triangles {
+		if count >= len(lod.Levels[len(lod.Levels)-1].Indices)/3 {
+			continue
+		}
+		// every level starts from the previous one, it is cheaper and keeps them nested
+		lod.Levels = append(lod.Levels, lod.Levels[len(lod.Levels)-1].Simplify(count))
+	}
+	for _, level := range lod.Levels {
+		share := float32(len(level.Indices)/3) / float32(maxInt(total, 1))
+		lod.ScreenSizes = append(lod.ScreenSizes, 0.5*math32.Sqrt(share))
+	}
+	return lod
+}
+
+//ScreenSize returns the fraction of the screen height covered by the bounding sphere of the
+//mesh drawn with model, seen through view and a perspective projection, 1 or more when the
+//camera is inside it.
+func (l *LOD) ScreenSize(model, view, projection mgl32.Mat4) float32 {
+	sphere := l.Bounds.Transform(model)
+	distance := mgl32.TransformCoordinate(sphere.Center, view).Len()
+	if distance <= sphere.Radius {
+		return 1
+	}
+	// projection[5] is the cotangent of half the vertical field of view
+	return sphere.Radius * projection[5] / distance
+}
+
+//Level returns the index of the level to draw at screenSize.
+func (l *LOD) Level(screenSize float32) int {
+	for i, size := range l.ScreenSizes {
+		if screenSize >= size {
+			return i
+		}
+	}
+	return len(l.Levels) - 1
+}
+
+//Select returns the level to draw the mesh with model, view and projection.
+func (l *LOD) Select(model, view, projection mgl32.Mat4) *Mesh {
+	return l.Levels[l.Level(l.ScreenSize(model, view, projection))]
+}
+
+//Upload uploads every level.
+func (l *LOD) Upload() error {
+	for _, level := range l.Levels {
+		if err := level.Upload(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Draw draws the level Select picks. The caller binds the program, textures and uniforms,
+//model included.
+func (l *LOD) Draw(model, view, projection mgl32.Mat4) {
+	l.Select(model, view, projection).Draw()
+}
+
+//Delete releases the vertex arrays of every level.
+func (l *LOD) Delete() {
+	for _, level := range l.Levels {
+		level.Delete()
+	}
+}
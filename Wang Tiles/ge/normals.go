@@ -0,0 +1,63 @@
+package ge
+
+import (
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//GetConstantNormals3 returns count copies of normal, for planes and the flat caps of the
+//round primitives.
+func GetConstantNormals3(normal mgl32.Vec3, count int) []mgl32.Vec3 {
+	normals := make([]mgl32.Vec3, count)
+	for i := range normals {
+		normals[i] = normal
+	}
+	return normals
+}
+
+//GetFlatNormals3 returns one normal per vertex of a triangle list, the same for the three
+//vertices of each triangle. Triangles are expected counter clockwise when seen from the front.
+func GetFlatNormals3(vertices []mgl32.Vec3) []mgl32.Vec3 {
+	normals := make([]mgl32.Vec3, len(vertices))
+	for i := 0; i+2 < len(vertices); i += 3 {
+		normal := vertices[i+1].Sub(vertices[i]).Cross(vertices[i+2].Sub(vertices[i]))
+		if normal.Len() > 0 {
+			normal = normal.Normalize()
+		}
+		normals[i], normals[i+1], normals[i+2] = normal, normal, normal
+	}
+	return normals
+}
+
+//getSphereNormals points every vertex away from center.
+func getSphereNormals(vertices []mgl32.Vec3, center mgl32.Vec3) []mgl32.Vec3 {
+	normals := make([]mgl32.Vec3, len(vertices))
+	for i, v := range vertices {
+		normals[i] = v.Sub(center).Normalize()
+	}
+	return normals
+}
+
+//getStripU is the horizontal texture coordinate of entry i of a strip built from pairs of
+//GetCircleVertices3 rings, 0 at the first column and 1 at the closing one.
+func getStripU(i, vertices int) float32 {
+	return float32((i/2)%(vertices+1)) / float32(vertices)
+}
+
+//getLatitude is the angle above the equator of a point at height y on a sphere of radius r.
+func getLatitude(y, r float32) float32 {
+	return math32.Asin(math32.Max(-1, math32.Min(1, y/r)))
+}
+
+//getFanTextureCoords maps a fan whose first vertex is a pole, v is given by height.
+func getFanTextureCoords(vertices []mgl32.Vec3, columns int, height func(mgl32.Vec3) float32) []mgl32.Vec2 {
+	tCoords := make([]mgl32.Vec2, len(vertices))
+	for i, v := range vertices {
+		u := float32(0.5)
+		if i > 0 {
+			u = float32(i-1) / float32(columns)
+		}
+		tCoords[i] = mgl32.Vec2{u, height(v)}
+	}
+	return tCoords
+}
@@ -0,0 +1,454 @@
+package ge
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.maze.io/go/math32"
+	"github.com/StevenTarazona/glcore/gfx"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//Material is a Wavefront MTL material.
+type Material struct {
+	Name      string
+	Ambient   mgl32.Vec3 // Ka
+	Diffuse   mgl32.Vec3 // Kd
+	Specular  mgl32.Vec3 // Ks
+	Shininess float32    // Ns
+	Opacity   float32    // d, or 1 - Tr
+	// DiffuseMap is the map_Kd file, relative to the working directory
+	DiffuseMap string
+	// Texture is loaded from DiffuseMap by Model.Upload
+	Texture *gfx.Texture
+}
+
+//Model is a set of meshes with one material each, as loaded by LoadOBJ.
+type Model struct {
+	Meshes []*Mesh
+	// Materials[i] is the material of Meshes[i], nil for faces without usemtl
+	Materials []*Material
+}
+
+//LoadOBJ reads a Wavefront OBJ file and the MTL libraries it references. Faces are split in one
+//mesh per material and polygons are triangulated. When weld is set, face corners with the same
+//position, texture and normal indices share a vertex, otherwise every corner gets its own.
+//Missing normals are computed, smooth across welded vertices and flat otherwise. Texture
+//coordinates are flipped vertically to match the top down images of gfx.NewTexture.
+func LoadOBJ(file string, weld bool) (*Model, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return readOBJ(in, file, weld)
+}
+
+//Upload uploads every mesh and loads the diffuse map of every material.
+func (m *Model) Upload() error {
+	textures := map[string]*gfx.Texture{}
+	for i, mesh := range m.Meshes {
+		if err := mesh.Upload(); err != nil {
+			return err
+		}
+		material := m.Materials[i]
+		if material == nil || material.DiffuseMap == "" || material.Texture != nil {
+			continue
+		}
+		texture, ok := textures[material.DiffuseMap]
+		if !ok {
+			var err error
+			texture, err = gfx.NewTextureFromFile(material.DiffuseMap, gl.REPEAT, gl.REPEAT)
+			if err != nil {
+				return err
+			}
+			textures[material.DiffuseMap] = texture
+		}
+		material.Texture = texture
+	}
+	return nil
+}
+
+//Draw draws every mesh. bind is called with the material of each mesh, which may be nil, so the
+//caller can set the uniforms of its shader; textures are bound to TEXTURE0 before that.
+func (m *Model) Draw(bind func(material *Material)) {
+	for i, mesh := range m.Meshes {
+		material := m.Materials[i]
+		if material != nil && material.Texture != nil {
+			material.Texture.Bind(gl.TEXTURE0)
+		}
+		if bind != nil {
+			bind(material)
+		}
+		mesh.Draw()
+		if material != nil && material.Texture != nil {
+			material.Texture.UnBind()
+		}
+	}
+}
+
+//Delete releases the meshes of the model. Textures are kept, they may be shared.
+func (m *Model) Delete() {
+	for _, mesh := range m.Meshes {
+		mesh.Delete()
+	}
+}
+
+type objCorner struct {
+	position, uv, normal int
+}
+
+type objBuilder struct {
+	mesh     *Mesh
+	material *Material
+	vertices map[objCorner]uint32
+	// generated[i] is set when vertex i has no normal in the file
+	generated []bool
+}
+
+func readOBJ(in io.Reader, file string, weld bool) (*Model, error) {
+	var positions, normals []mgl32.Vec3
+	var uvs []mgl32.Vec2
+	materials := map[string]*Material{}
+	builders := []*objBuilder{}
+	var current *objBuilder
+
+	use := func(material *Material) {
+		for _, builder := range builders {
+			if builder.material == material {
+				current = builder
+				return
+			}
+		}
+		current = &objBuilder{mesh: &Mesh{}, material: material, vertices: map[objCorner]uint32{}}
+		builders = append(builders, current)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "v":
+			var v mgl32.Vec3
+			v, err = parseVec3(fields[1:])
+			positions = append(positions, v)
+		case "vn":
+			var n mgl32.Vec3
+			n, err = parseVec3(fields[1:])
+			normals = append(normals, n)
+		case "vt":
+			var uv []float32
+			if uv, err = parseFloats(fields[1:], 1); err == nil {
+				uv = append(uv, 0)
+				uvs = append(uvs, mgl32.Vec2{uv[0], 1 - uv[1]})
+			}
+		case "f":
+			if current == nil {
+				use(nil)
+			}
+			corners := make([]objCorner, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				var corner objCorner
+				corner, err = parseOBJCorner(field, len(positions), len(uvs), len(normals))
+				if err != nil {
+					break
+				}
+				corners = append(corners, corner)
+			}
+			if err == nil {
+				err = current.addFace(corners, positions, uvs, normals, weld)
+			}
+		case "mtllib":
+			for _, library := range fields[1:] {
+				if err = loadMTL(filepath.Join(filepath.Dir(file), library), materials); err != nil {
+					break
+				}
+			}
+		case "usemtl":
+			name := strings.Join(fields[1:], " ")
+			material, ok := materials[name]
+			if !ok {
+				err = fmt.Errorf("unknown material %q", name)
+				break
+			}
+			use(material)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", file, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	model := &Model{}
+	for _, builder := range builders {
+		if len(builder.mesh.Indices) == 0 {
+			continue
+		}
+		builder.finish()
+		model.Meshes = append(model.Meshes, builder.mesh)
+		model.Materials = append(model.Materials, builder.material)
+	}
+	return model, nil
+}
+
+//addFace triangulates a polygon and adds its corners to the mesh.
+func (b *objBuilder) addFace(corners []objCorner, positions []mgl32.Vec3, uvs []mgl32.Vec2, normals []mgl32.Vec3, weld bool) error {
+	if len(corners) < 3 {
+		return fmt.Errorf("face with %d vertices", len(corners))
+	}
+	polygon := make([]mgl32.Vec3, len(corners))
+	for i, corner := range corners {
+		polygon[i] = positions[corner.position]
+	}
+	faceNormal := getPolygonNormal(polygon)
+
+	indices := make([]uint32, len(corners))
+	for i, corner := range corners {
+		if index, ok := b.vertices[corner]; ok && weld {
+			indices[i] = index
+			if b.generated[index] {
+				// area weighted, normalized in finish
+				b.mesh.Normals[index] = b.mesh.Normals[index].Add(faceNormal)
+			}
+			continue
+		}
+		index := uint32(len(b.mesh.Positions))
+		b.mesh.Positions = append(b.mesh.Positions, positions[corner.position])
+		uv := mgl32.Vec2{}
+		if corner.uv >= 0 {
+			uv = uvs[corner.uv]
+		}
+		b.mesh.UVs = append(b.mesh.UVs, uv)
+		if corner.normal >= 0 {
+			b.mesh.Normals = append(b.mesh.Normals, normals[corner.normal])
+		} else {
+			b.mesh.Normals = append(b.mesh.Normals, faceNormal)
+		}
+		b.generated = append(b.generated, corner.normal < 0)
+		b.vertices[corner] = index
+		indices[i] = index
+	}
+	for _, triangle := range triangulatePolygon(polygon, faceNormal) {
+		b.mesh.Indices = append(b.mesh.Indices, indices[triangle[0]], indices[triangle[1]], indices[triangle[2]])
+	}
+	return nil
+}
+
+func (b *objBuilder) finish() {
+	for i, generated := range b.generated {
+		if generated && b.mesh.Normals[i].Len() > 0 {
+			b.mesh.Normals[i] = b.mesh.Normals[i].Normalize()
+		}
+	}
+}
+
+//getPolygonNormal is the Newell normal of a polygon, its length is twice the polygon area.
+func getPolygonNormal(polygon []mgl32.Vec3) mgl32.Vec3 {
+	var normal mgl32.Vec3
+	for i, current := range polygon {
+		next := polygon[(i+1)%len(polygon)]
+		normal = normal.Add(mgl32.Vec3{
+			(current.Y() - next.Y()) * (current.Z() + next.Z()),
+			(current.Z() - next.Z()) * (current.X() + next.X()),
+			(current.X() - next.X()) * (current.Y() + next.Y()),
+		})
+	}
+	return normal
+}
+
+//triangulatePolygon splits a planar polygon by ear clipping, keeping the winding of normal.
+//Triangles are returned as indices into polygon.
+func triangulatePolygon(polygon []mgl32.Vec3, normal mgl32.Vec3) [][3]int {
+	if len(polygon) == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+	// project on the plane most facing the normal
+	x, y := 0, 1
+	switch {
+	case math32.Abs(normal.X()) >= math32.Abs(normal.Y()) && math32.Abs(normal.X()) >= math32.Abs(normal.Z()):
+		x, y = 1, 2
+	case math32.Abs(normal.Y()) >= math32.Abs(normal.Z()):
+		x, y = 2, 0
+	}
+	points := make([]mgl32.Vec2, len(polygon))
+	for i, v := range polygon {
+		points[i] = mgl32.Vec2{v[x], v[y]}
+	}
+	// the projection keeps the winding when the dropped axis of the normal is positive
+	sign := float32(1)
+	if normal[3-x-y] < 0 {
+		sign = -1
+	}
+	cross := func(a, b, c mgl32.Vec2) float32 {
+		return sign * ((b.X()-a.X())*(c.Y()-a.Y()) - (b.Y()-a.Y())*(c.X()-a.X()))
+	}
+
+	remaining := make([]int, len(polygon))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	triangles := [][3]int{}
+	for len(remaining) > 3 {
+		found := false
+		for i := range remaining {
+			prev, current, next := remaining[(i+len(remaining)-1)%len(remaining)], remaining[i], remaining[(i+1)%len(remaining)]
+			a, b, c := points[prev], points[current], points[next]
+			if cross(a, b, c) <= 0 {
+				continue
+			}
+			ear := true
+			for _, other := range remaining {
+				if other == prev || other == current || other == next {
+					continue
+				}
+				p := points[other]
+				if cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0 {
+					ear = false
+					break
+				}
+			}
+			if ear {
+				triangles = append(triangles, [3]int{prev, current, next})
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			// degenerate or self intersecting, fall back to a fan
+			for i := 1; i+1 < len(remaining); i++ {
+				triangles = append(triangles, [3]int{remaining[0], remaining[i], remaining[i+1]})
+			}
+			return triangles
+		}
+	}
+	return append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+}
+
+//parseOBJCorner parses v, v/vt, v//vn or v/vt/vn into zero based indices, -1 when missing.
+func parseOBJCorner(field string, positions, uvs, normals int) (objCorner, error) {
+	corner := objCorner{-1, -1, -1}
+	parts := strings.Split(field, "/")
+	counts := []int{positions, uvs, normals}
+	targets := []*int{&corner.position, &corner.uv, &corner.normal}
+	for i, part := range parts {
+		if i > 2 {
+			return corner, fmt.Errorf("bad face vertex %q", field)
+		}
+		if part == "" {
+			continue
+		}
+		index, err := strconv.Atoi(part)
+		if err != nil {
+			return corner, fmt.Errorf("bad face vertex %q", field)
+		}
+		// negative indices count back from the last element read
+		if index < 0 {
+			index += counts[i]
+		} else {
+			index--
+		}
+		if index < 0 || index >= counts[i] {
+			return corner, fmt.Errorf("face vertex %q out of range", field)
+		}
+		*targets[i] = index
+	}
+	if corner.position < 0 {
+		return corner, fmt.Errorf("face vertex %q has no position", field)
+	}
+	return corner, nil
+}
+
+//loadMTL adds the materials of an MTL file to materials.
+func loadMTL(file string, materials map[string]*Material) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var material *Material
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "newmtl" {
+			material = &Material{Name: strings.Join(fields[1:], " "), Diffuse: mgl32.Vec3{1, 1, 1}, Opacity: 1}
+			materials[material.Name] = material
+			continue
+		}
+		if material == nil {
+			continue
+		}
+		switch fields[0] {
+		case "Ka":
+			material.Ambient, err = parseVec3(fields[1:])
+		case "Kd":
+			material.Diffuse, err = parseVec3(fields[1:])
+		case "Ks":
+			material.Specular, err = parseVec3(fields[1:])
+		case "Ns":
+			material.Shininess, err = parseFloat(fields[1:])
+		case "d":
+			material.Opacity, err = parseFloat(fields[1:])
+		case "Tr":
+			var transparency float32
+			transparency, err = parseFloat(fields[1:])
+			material.Opacity = 1 - transparency
+		case "map_Kd":
+			// options come first, the file name is last
+			if len(fields) > 1 {
+				material.DiffuseMap = filepath.Join(filepath.Dir(file), fields[len(fields)-1])
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", file, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func parseFloats(fields []string, min int) ([]float32, error) {
+	if len(fields) < min {
+		return nil, fmt.Errorf("expected %d numbers, got %d", min, len(fields))
+	}
+	values := make([]float32, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = float32(value)
+	}
+	return values, nil
+}
+
+func parseFloat(fields []string) (float32, error) {
+	values, err := parseFloats(fields, 1)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+func parseVec3(fields []string) (mgl32.Vec3, error) {
+	values, err := parseFloats(fields, 3)
+	if err != nil {
+		return mgl32.Vec3{}, err
+	}
+	return mgl32.Vec3{values[0], values[1], values[2]}, nil
+}
@@ -1,7 +1,9 @@
 package ge
 
 import (
-	"math/rand"
+	"fmt"
+	"image"
+	"math"
 	"time"
 
 	"git.maze.io/go/math32"
@@ -20,6 +22,15 @@ func GetCircleVertices3(r float32, vertices int) (unitCircleVertices []mgl32.Vec
 	return
 }
 
+//GetCircleTextureCoords maps the vertices of GetCircleVertices3 to the unit square, with the
+//centre at (0.5, 0.5).
+func GetCircleTextureCoords(vertices int) (tCoords []mgl32.Vec2) {
+	for _, v := range GetCircleVertices3(0.5, vertices) {
+		tCoords = append(tCoords, mgl32.Vec2{v.X() + 0.5, v.Z() + 0.5})
+	}
+	return
+}
+
 //GetRingVerticies3 ...
 func GetRingVerticies3(rIn float32, rOut float32, vertices int) (ring []mgl32.Vec3) {
 	in := GetCircleVertices3(rIn, vertices)
@@ -31,6 +42,15 @@ func GetRingVerticies3(rIn float32, rOut float32, vertices int) (ring []mgl32.Ve
 	return
 }
 
+//GetRingTextureCoords maps the vertices of GetRingVerticies3 to the unit square, like
+//GetCircleTextureCoords does for a circle of radius rOut.
+func GetRingTextureCoords(rIn float32, rOut float32, vertices int) (tCoords []mgl32.Vec2) {
+	for _, v := range GetRingVerticies3(0.5*rIn/rOut, 0.5, vertices) {
+		tCoords = append(tCoords, mgl32.Vec2{v.X() + 0.5, v.Z() + 0.5})
+	}
+	return
+}
+
 //GetCylinderVertices3 ...
 func GetCylinderVertices3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
 	var slices int
@@ -62,6 +82,32 @@ func GetCylinderVertices3(h float32, rBottom float32, rTop float32, vertices int
 	return
 }
 
+//GetCylinderNormals3 returns smooth normals for the vertices of GetCylinderVertices3, tilted
+//along the slope of the side when rBottom and rTop differ.
+func GetCylinderNormals3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetCylinderVertices3(h, rBottom, rTop, vertices)
+	slope := (rBottom - rTop) / h
+	for i := range sideVertices {
+		angle := getStripU(i, vertices) * 2 * math32.Pi
+		side = append(side, mgl32.Vec3{math32.Cos(angle), slope, math32.Sin(angle)}.Normalize())
+	}
+	top = GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(topVertices))
+	bottom = GetConstantNormals3(mgl32.Vec3{0, -1, 0}, len(bottomVertices))
+	return
+}
+
+//GetCylinderTextureCoords wraps the unit square once around the side of GetCylinderVertices3
+//and maps the caps like GetCircleTextureCoords.
+func GetCylinderTextureCoords(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, _, _ := GetCylinderVertices3(h, rBottom, rTop, vertices)
+	for i, v := range sideVertices {
+		side = append(side, mgl32.Vec2{getStripU(i, vertices), v.Y() / h})
+	}
+	top = GetCircleTextureCoords(vertices)
+	bottom = GetCircleTextureCoords(vertices)
+	return
+}
+
 //GetPipeVertices3 ...
 func GetPipeVertices3(h float32, rIn float32, rOut float32, vertices int) (sideIn, sideOut, top, bottom []mgl32.Vec3) {
 	var slices int
@@ -85,6 +131,33 @@ func GetPipeVertices3(h float32, rIn float32, rOut float32, vertices int) (sideI
 	return
 }
 
+//GetPipeNormals3 returns normals for the vertices of GetPipeVertices3, pointing inwards on the
+//inner side and outwards on the outer one.
+func GetPipeNormals3(h float32, rIn float32, rOut float32, vertices int) (sideIn, sideOut, top, bottom []mgl32.Vec3) {
+	sideInVertices, _, topVertices, bottomVertices := GetPipeVertices3(h, rIn, rOut, vertices)
+	for i := range sideInVertices {
+		angle := getStripU(i, vertices) * 2 * math32.Pi
+		sideOut = append(sideOut, mgl32.Vec3{math32.Cos(angle), 0, math32.Sin(angle)})
+		sideIn = append(sideIn, mgl32.Vec3{-math32.Cos(angle), 0, -math32.Sin(angle)})
+	}
+	top = GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(topVertices))
+	bottom = GetConstantNormals3(mgl32.Vec3{0, -1, 0}, len(bottomVertices))
+	return
+}
+
+//GetPipeTextureCoords wraps the unit square around both sides of GetPipeVertices3 and maps
+//the rings like GetRingTextureCoords.
+func GetPipeTextureCoords(h float32, rIn float32, rOut float32, vertices int) (sideIn, sideOut, top, bottom []mgl32.Vec2) {
+	sideInVertices, _, _, _ := GetPipeVertices3(h, rIn, rOut, vertices)
+	for i, v := range sideInVertices {
+		sideIn = append(sideIn, mgl32.Vec2{getStripU(i, vertices), v.Y() / h})
+	}
+	sideOut = sideIn
+	top = GetRingTextureCoords(rIn, rOut, vertices)
+	bottom = top
+	return
+}
+
 //GetSemiSphereVertices3 ...
 func GetSemiSphereVertices3(r float32, vertices int) (side, top, bottom []mgl32.Vec3) {
 	auxR := r
@@ -110,6 +183,32 @@ func GetSemiSphereVertices3(r float32, vertices int) (side, top, bottom []mgl32.
 	return
 }
 
+//GetSemiSphereNormals3 returns smooth normals for the vertices of GetSemiSphereVertices3, the
+//flat bottom faces down.
+func GetSemiSphereNormals3(r float32, vertices int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetSemiSphereVertices3(r, vertices)
+	side = getSphereNormals(sideVertices, mgl32.Vec3{})
+	top = getSphereNormals(topVertices, mgl32.Vec3{})
+	bottom = GetConstantNormals3(mgl32.Vec3{0, -1, 0}, len(bottomVertices))
+	return
+}
+
+//GetSemiSphereTextureCoords maps the vertices of GetSemiSphereVertices3 by longitude and
+//latitude, from the rim at v = 0 to the pole at v = 1. The bottom is mapped like
+//GetCircleTextureCoords.
+func GetSemiSphereTextureCoords(r float32, vertices int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, topVertices, _ := GetSemiSphereVertices3(r, vertices)
+	latitude := func(v mgl32.Vec3) float32 {
+		return getLatitude(v.Y(), r) / (math32.Pi / 2)
+	}
+	for i, v := range sideVertices {
+		side = append(side, mgl32.Vec2{getStripU(i, vertices), latitude(v)})
+	}
+	top = getFanTextureCoords(topVertices, vertices, latitude)
+	bottom = GetCircleTextureCoords(vertices)
+	return
+}
+
 //GetSphereVertices3 ...
 func GetSphereVertices3(r float32, numVertex int) (side, top, bottom []mgl32.Vec3) {
 	semiSphere, top, _ := GetSemiSphereVertices3(r, numVertex)
@@ -126,6 +225,34 @@ func GetSphereVertices3(r float32, numVertex int) (side, top, bottom []mgl32.Vec
 	return
 }
 
+//GetSphereNormals3 returns smooth normals for the vertices of GetSphereVertices3.
+func GetSphereNormals3(r float32, numVertex int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetSphereVertices3(r, numVertex)
+	center := mgl32.Vec3{0, r, 0}
+	return getSphereNormals(sideVertices, center), getSphereNormals(topVertices, center), getSphereNormals(bottomVertices, center)
+}
+
+//GetSphereTextureCoords maps the vertices of GetSphereVertices3 by longitude and latitude,
+//from the bottom pole at v = 0 to the top one at v = 1.
+func GetSphereTextureCoords(r float32, numVertex int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, topVertices, bottomVertices := GetSphereVertices3(r, numVertex)
+	latitude := func(v mgl32.Vec3) float32 {
+		return 0.5 + getLatitude(v.Y()-r, r)/math32.Pi
+	}
+	// the side is the bottom half reversed followed by the top half
+	half := len(sideVertices) / 2
+	for i, v := range sideVertices {
+		column := i - half
+		if i < half {
+			column = half - 1 - i
+		}
+		side = append(side, mgl32.Vec2{getStripU(column, numVertex), latitude(v)})
+	}
+	top = getFanTextureCoords(topVertices, numVertex, latitude)
+	bottom = getFanTextureCoords(bottomVertices, numVertex, latitude)
+	return
+}
+
 //GetCapsuleVertices3 ...
 func GetCapsuleVertices3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
 	sideTemp, _, _ := GetCylinderVertices3(h-rBottom-rTop, rBottom, rTop, vertices)
@@ -145,6 +272,42 @@ func GetCapsuleVertices3(h float32, rBottom float32, rTop float32, vertices int)
 	return
 }
 
+//GetCapsuleNormals3 returns smooth normals for the vertices of GetCapsuleVertices3.
+func GetCapsuleNormals3(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec3) {
+	sideVertices, topVertices, bottomVertices := GetCapsuleVertices3(h, rBottom, rTop, vertices)
+	bottomSide, _, _ := GetSemiSphereVertices3(rBottom, vertices)
+	cylinderSide, _, _ := GetCylinderNormals3(h-rBottom-rTop, rBottom, rTop, vertices)
+	bottomCenter, topCenter := mgl32.Vec3{0, rBottom, 0}, mgl32.Vec3{0, h - rTop, 0}
+
+	side = append(side, getSphereNormals(sideVertices[:len(bottomSide)], bottomCenter)...)
+	side = append(side, cylinderSide...)
+	side = append(side, getSphereNormals(sideVertices[len(bottomSide)+len(cylinderSide):], topCenter)...)
+	top = getSphereNormals(topVertices, topCenter)
+	bottom = getSphereNormals(bottomVertices, bottomCenter)
+	return
+}
+
+//GetCapsuleTextureCoords wraps the unit square once around GetCapsuleVertices3, from the
+//bottom pole at v = 0 to the top one at v = 1.
+func GetCapsuleTextureCoords(h float32, rBottom float32, rTop float32, vertices int) (side, top, bottom []mgl32.Vec2) {
+	sideVertices, topVertices, bottomVertices := GetCapsuleVertices3(h, rBottom, rTop, vertices)
+	bottomSide, _, _ := GetSemiSphereVertices3(rBottom, vertices)
+	height := func(v mgl32.Vec3) float32 {
+		return v.Y() / h
+	}
+	// the bottom cap side is reversed, the cylinder and top cap sides follow in order
+	for i, v := range sideVertices {
+		column := i - len(bottomSide)
+		if i < len(bottomSide) {
+			column = len(bottomSide) - 1 - i
+		}
+		side = append(side, mgl32.Vec2{getStripU(column, vertices), height(v)})
+	}
+	top = getFanTextureCoords(topVertices, vertices, height)
+	bottom = getFanTextureCoords(bottomVertices, vertices, height)
+	return
+}
+
 //GetCubicHexahedronVertices3 ...
 func GetCubicHexahedronVertices3(X, Y, Z float32) []mgl32.Vec3 {
 	var vertices = []mgl32.Vec3{
@@ -164,6 +327,12 @@ func GetCubicHexahedronVertices3(X, Y, Z float32) []mgl32.Vec3 {
 	return vertices
 }
 
+//GetCubicHexahedronNormals3 returns flat normals for the vertices of
+//GetCubicHexahedronVertices3.
+func GetCubicHexahedronNormals3(X, Y, Z float32) []mgl32.Vec3 {
+	return GetFlatNormals3(GetCubicHexahedronVertices3(X, Y, Z))
+}
+
 //GetCubicHexahedronTextureCoords ...
 func GetCubicHexahedronTextureCoords(X, Y, Z float32) []mgl32.Vec2 {
 	var vertices = []mgl32.Vec2{
@@ -199,6 +368,33 @@ func GetSquare(hTiles int, vTiles int, tileLengths float32) (vertices []mgl32.Ve
 	return
 }
 
+//GetSquareMesh is GetSquare as a mesh facing up.
+func GetSquareMesh(hTiles int, vTiles int, tileLengths float32) *Mesh {
+	vertices, tCoords, indices := GetSquare(hTiles, vTiles, tileLengths)
+	return NewMesh(vertices, GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(vertices)), tCoords, indices)
+}
+
+//GetSquarePatches builds the GetSquare plane as quad patches for tessellation shaders, drawn
+//with DrawPatches(4). Every patch lists its corners h, v then h+1, v then h+1, v+1 then h, v+1,
+//so gl_TessCoord.x runs along x and gl_TessCoord.y along z, and quads tessellated with cw
+//winding face up.
+func GetSquarePatches(hTiles int, vTiles int, tileLengths float32) (vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	vOfset := (float32(vTiles) * tileLengths) / 2
+	hOfset := (float32(hTiles) * tileLengths) / 2
+	for v := 0; v <= vTiles; v++ {
+		for h := 0; h <= hTiles; h++ {
+			vertices = append(vertices, mgl32.Vec3{(float32(h) * tileLengths) - hOfset, 0, (float32(v) * tileLengths) - vOfset})
+			tCoords = append(tCoords, mgl32.Vec2{float32(h) / float32(hTiles), float32(v) / float32(vTiles)})
+			if h < hTiles && v < vTiles {
+				first := uint32(h + (hTiles+1)*v)
+				next := first + uint32(hTiles+1)
+				indices = append(indices, first, first+1, next+1, next)
+			}
+		}
+	}
+	return
+}
+
 //GetSquareRepeat ...
 func GetSquareRepeat(hTiles int, vTiles int, tileLengths float32) (vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
 	vOfset := (float32(vTiles) * tileLengths) / 2
@@ -218,25 +414,31 @@ func GetSquareRepeat(hTiles int, vTiles int, tileLengths float32) (vertices []mg
 	return
 }
 
-//GetSquareWangTiles ...
-func GetSquareWangTiles(hTiles int, vTiles int, tileLengths float32, tileCords [][]mgl32.Vec2, adjacencyList [][][]int) (vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
-	rand.Seed(time.Now().UnixNano())
-	vOfset := (float32(vTiles) * tileLengths) / 2
-	hOfset := (float32(hTiles) * tileLengths) / 2
-
-	possibilities := [][][]int{}
-	for v := 0; v < vTiles; v++ {
-		possibilities = append(possibilities, [][]int{})
-		for h := 0; h < hTiles; h++ {
-			possibilities[v] = append(possibilities[v], []int{})
-		}
+//GetSquareWangTiles builds a hTiles x vTiles plane textured with tiles chosen so that every
+//right and bottom neighbour is allowed by adjacencyList, or returns an error naming the cell
+//that could not be satisfied. Use NewWangGenerator and GetSquareTiles for reproducible maps.
+func GetSquareWangTiles(hTiles int, vTiles int, tileLengths float32, tileCords [][]mgl32.Vec2, adjacencyList [][][]int) (vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32, err error) {
+	if len(adjacencyList) != len(tileCords) {
+		return nil, nil, nil, fmt.Errorf("wang tiles: %d tiles but %d adjacency entries", len(tileCords), len(adjacencyList))
 	}
+	tiles, err := NewWangGenerator(adjacencyList, time.Now().UnixNano()).Generate(hTiles, vTiles)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	vertices, tCoords, indices = GetSquareTiles(tiles, tileLengths, tileCords)
+	return
+}
 
-	all := []int{}
-	for i := range tileCords {
-		all = append(all, i)
+//GetSquareTiles builds a plane with one quad per entry of tiles, indexed [v][h], textured with
+//the matching tileCords entry.
+func GetSquareTiles(tiles [][]int, tileLengths float32, tileCords [][]mgl32.Vec2) (vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	vTiles := len(tiles)
+	if vTiles == 0 {
+		return
 	}
-	possibilities[0][0] = all
+	hTiles := len(tiles[0])
+	vOfset := (float32(vTiles) * tileLengths) / 2
+	hOfset := (float32(hTiles) * tileLengths) / 2
 
 	for v := 0; v < vTiles; v++ {
 		for h := 0; h < hTiles; h++ {
@@ -255,42 +457,376 @@ func GetSquareWangTiles(hTiles int, vTiles int, tileLengths float32, tileCords [
 				uint32(firstIndex + 2),
 				uint32(firstIndex + 1),
 			}...)
+			tCoords = append(tCoords, tileCords[tiles[v][h]]...)
+		}
+	}
+	return
+}
 
-			currentPossibilities := possibilities[h][v]
-			currentTile := currentPossibilities[rand.Intn(len(currentPossibilities))]
-			tCoords = append(tCoords, tileCords[currentTile]...)
+//GetSquareTilesMesh is GetSquareTiles as a mesh facing up.
+func GetSquareTilesMesh(tiles [][]int, tileLengths float32, tileCords [][]mgl32.Vec2) *Mesh {
+	vertices, tCoords, indices := GetSquareTiles(tiles, tileLengths, tileCords)
+	return NewMesh(vertices, GetConstantNormals3(mgl32.Vec3{0, 1, 0}, len(vertices)), tCoords, indices)
+}
 
-			if (h < hTiles-1 && v < vTiles-1) || (h == hTiles-1 && v < vTiles-1) {
-				//Bottom [1]
-				if len(possibilities[h][v+1]) == 0 {
-					possibilities[h][v+1] = adjacencyList[currentTile][1]
-				} else {
-					possibilities[h][v+1] = andArray(possibilities[h+1][v], adjacencyList[currentTile][1])
-				}
+func GetSquareStrip(hTiles int, vTiles int, tileLength float32) ([]float32, []float32, []uint32) {
+	vertices := make([]float32, 0, (hTiles+1)*(vTiles+1)*3)
+	tCoords := make([]float32, 0, (hTiles+1)*(vTiles+1)*3)
+	indices := make([]uint32, 0, (hTiles+1)*vTiles*2)
+	vOfset := (float32(vTiles) * tileLength) / 2
+	hOfset := (float32(hTiles) * tileLength) / 2
+	for v := 0; v <= vTiles; v++ {
+		for h := 0; h <= hTiles; h++ {
+			newH := h
+			if v%2 != 0 {
+				newH = hTiles - h
+			}
+			vertices = append(vertices, []float32{(float32(h) * tileLength) - hOfset, 0, (float32(v) * tileLength) - vOfset}...)
+			tCoords = append(tCoords, []float32{(float32(h) * (1 / float32(hTiles))), (float32(v) * (1 / float32(vTiles)))}...)
+			if v < vTiles {
+				indices = append(indices, []uint32{
+					uint32(newH + (hTiles+1)*v),
+					uint32(newH + (hTiles+1)*(v+1)),
+				}...)
+			}
+		}
+	}
+	return vertices, tCoords, indices
+}
+
+func GetSquareStripDisplaced(hTiles int, vTiles int, tileLength float32, img image.Image, magnitud float32) ([]float32, []float32, []uint32) {
+	vertices := make([]float32, 0, (hTiles+1)*(vTiles+1)*3)
+	tCoords := make([]float32, 0, (hTiles+1)*(vTiles+1)*3)
+	indices := make([]uint32, 0, (hTiles+1)*vTiles*2)
+	vOfset := (float32(vTiles) * tileLength) / 2
+	hOfset := (float32(hTiles) * tileLength) / 2
+	for v := 0; v <= vTiles; v++ {
+		for h := 0; h <= hTiles; h++ {
+			newH := h
+			if v%2 != 0 {
+				newH = hTiles - h
+			}
+			tCoordH, tCoordV := (float32(h) * (1 / float32(hTiles))), (float32(v) * (1 / float32(vTiles)))
+			vertices = append(vertices, []float32{(float32(h) * tileLength) - hOfset, getDisplacemente(img, magnitud, tCoordH, tCoordV), (float32(v) * tileLength) - vOfset}...)
+			tCoords = append(tCoords, []float32{tCoordH, tCoordV}...)
+			if v < vTiles {
+				indices = append(indices, []uint32{
+					uint32(newH + (hTiles+1)*v),
+					uint32(newH + (hTiles+1)*(v+1)),
+				}...)
+			}
+		}
+	}
+	return vertices, tCoords, indices
+}
+
+func getDisplacemente(img image.Image, magnitud, h, v float32) float32 {
+	imgBound := img.Bounds()
+	imgWidth := float32(imgBound.Max.X)
+	imgHeight := float32(imgBound.Max.Y)
+	pos := img.At(int(imgWidth*h), int(imgHeight*v))
+	r, g, b, _ := pos.RGBA()
+	lum := (19595*r + 38470*g + 7471*b + 1<<15) >> 24
+	return (magnitud * (float32(lum) / 255))
+}
+
+//GetTorus builds a torus lying on the ground around the y axis, rings segments around the axis
+//and sides around the tube. u follows the rings from the x axis like GetCircleVertices3 and v
+//goes around the tube from its inner equator over the top.
+func GetTorus(rMajor float32, rMinor float32, rings int, sides int) (vertices []mgl32.Vec3, normals []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	for i := 0; i <= rings; i++ {
+		theta := 2 * math32.Pi * float32(i) / float32(rings)
+		direction := mgl32.Vec3{math32.Cos(theta), 0, math32.Sin(theta)}
+		for j := 0; j <= sides; j++ {
+			phi := math32.Pi - 2*math32.Pi*float32(j)/float32(sides)
+			normal := direction.Mul(math32.Cos(phi)).Add(mgl32.Vec3{0, math32.Sin(phi), 0})
+			vertices = append(vertices, direction.Mul(rMajor).Add(normal.Mul(rMinor)).Add(mgl32.Vec3{0, rMinor, 0}))
+			normals = append(normals, normal)
+			tCoords = append(tCoords, mgl32.Vec2{float32(i) / float32(rings), float32(j) / float32(sides)})
+		}
+	}
+	indices = getGridQuads(rings, sides)
+	return
+}
+
+//GetTorusMesh is GetTorus as a mesh.
+func GetTorusMesh(rMajor float32, rMinor float32, rings int, sides int) *Mesh {
+	return NewMesh(GetTorus(rMajor, rMinor, rings, sides))
+}
+
+//GetIcosphere builds a sphere of radius r resting on the ground from an icosahedron whose edges
+//are split in halves subdivisions times. Its triangles are all about the same size, unlike
+//GetSphereVertices3 around the poles. Texture coordinates are mapped like GetSphereTextureCoords,
+//vertices on the seam and the poles are repeated so no triangle wraps around the texture.
+func GetIcosphere(r float32, subdivisions int) (vertices []mgl32.Vec3, normals []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	units, triangles := getGeodesicSphere(1 << uint(subdivisions))
+	vertices, normals, tCoords, indices = getSphereMapping(units, triangles, r, func(unit mgl32.Vec3) float32 {
+		return 0.5 + getLatitude(unit.Y(), 1)/math32.Pi
+	})
+	return Translate(vertices, mgl32.Vec3{0, r, 0}), normals, tCoords, indices
+}
+
+//GetIcosphereMesh is GetIcosphere as a mesh.
+func GetIcosphereMesh(r float32, subdivisions int) *Mesh {
+	return NewMesh(GetIcosphere(r, subdivisions))
+}
+
+//GetGeodesicDome builds the upper half of a geodesic sphere of radius r, open at the bottom,
+//with every edge of the icosahedron split in frequency parts. Even frequencies have a ring of
+//vertices on the ground, odd ones get the vertices below it lifted onto it. v goes from 0 on
+//the ground to 1 on the top.
+func GetGeodesicDome(r float32, frequency int) (vertices []mgl32.Vec3, normals []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	units, triangles := getGeodesicSphere(frequency)
+	kept := []uint32{}
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := units[triangles[i]], units[triangles[i+1]], units[triangles[i+2]]
+		if a.Y()+b.Y()+c.Y() > 0 {
+			kept = append(kept, triangles[i:i+3]...)
+		}
+	}
+	vertices, normals, tCoords, indices = getSphereMapping(units, kept, r, func(unit mgl32.Vec3) float32 {
+		return math32.Max(0, 2*getLatitude(unit.Y(), 1)/math32.Pi)
+	})
+	for i := range vertices {
+		vertices[i][1] = math32.Max(0, vertices[i][1])
+	}
+	return
+}
+
+//GetGeodesicDomeMesh is GetGeodesicDome as a mesh.
+func GetGeodesicDomeMesh(r float32, frequency int) *Mesh {
+	return NewMesh(GetGeodesicDome(r, frequency))
+}
+
+//GetRoundedBox builds an X x Y x Z box resting on the ground, like GetCubicHexahedronVertices3,
+//with its edges and corners rounded by radius using segments steps. Every face is mapped to
+//the whole texture.
+func GetRoundedBox(X, Y, Z float32, radius float32, segments int) (vertices []mgl32.Vec3, normals []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	radius = math32.Min(radius, math32.Min(X, math32.Min(Y, Z))/2)
+	half := mgl32.Vec3{X / 2, Y / 2, Z / 2}
+	inner := half.Sub(mgl32.Vec3{radius, radius, radius})
+	// every face is a grid on the outer box, its points are pushed onto a sphere of radius
+	// around the nearest point of the inner box, tan spacing keeps the steps of the round
+	// parts at equal angles
+	coords := func(axis int) (values []float32) {
+		add := func(value float32) {
+			if len(values) == 0 || value > values[len(values)-1]+1e-6 {
+				values = append(values, value)
 			}
-			if (h < hTiles-1 && v < vTiles-1) || (v == vTiles-1 && h < hTiles-1) {
-				//Right [0]
-				if len(possibilities[h+1][v]) == 0 {
-					possibilities[h+1][v] = adjacencyList[currentTile][0]
-				} else {
-					possibilities[h+1][v] = andArray(possibilities[h+1][v], adjacencyList[currentTile][0])
+		}
+		for k := segments; k > 0; k-- {
+			add(-inner[axis] - radius*math32.Tan(math32.Pi/4*float32(k)/float32(segments)))
+		}
+		add(-inner[axis])
+		add(inner[axis])
+		for k := 1; k <= segments; k++ {
+			add(inner[axis] + radius*math32.Tan(math32.Pi/4*float32(k)/float32(segments)))
+		}
+		return
+	}
+	faces := []struct {
+		normal mgl32.Vec3
+		u, v   int
+	}{
+		{mgl32.Vec3{1, 0, 0}, 1, 2}, {mgl32.Vec3{-1, 0, 0}, 2, 1},
+		{mgl32.Vec3{0, 1, 0}, 2, 0}, {mgl32.Vec3{0, -1, 0}, 0, 2},
+		{mgl32.Vec3{0, 0, 1}, 0, 1}, {mgl32.Vec3{0, 0, -1}, 1, 0},
+	}
+	for _, face := range faces {
+		us, vs := coords(face.u), coords(face.v)
+		first := uint32(len(vertices))
+		for _, u := range us {
+			for _, v := range vs {
+				p := mgl32.Vec3{face.normal[0] * half[0], face.normal[1] * half[1], face.normal[2] * half[2]}
+				p[face.u], p[face.v] = u, v
+				center := p
+				for axis := range center {
+					center[axis] = math32.Max(-inner[axis], math32.Min(inner[axis], center[axis]))
+				}
+				// a radius of 0 leaves the flat faces of a plain box
+				normal := face.normal
+				if offset := p.Sub(center); offset.Len() > 0 {
+					normal = offset.Normalize()
 				}
+				vertices = append(vertices, center.Add(normal.Mul(radius)).Add(mgl32.Vec3{0, Y / 2, 0}))
+				normals = append(normals, normal)
+				tCoords = append(tCoords, mgl32.Vec2{(u + half[face.u]) / (2 * half[face.u]), (v + half[face.v]) / (2 * half[face.v])})
 			}
 		}
+		for _, index := range getGridQuads(len(us)-1, len(vs)-1) {
+			indices = append(indices, first+index)
+		}
 	}
 	return
 }
 
-func andArray(a1, a2 []int) (a []int) {
-	for _, i := range a1 {
-		for _, j := range a2 {
-			if i == j {
-				a = append(a, i)
+//GetRoundedBoxMesh is GetRoundedBox as a mesh.
+func GetRoundedBoxMesh(X, Y, Z float32, radius float32, segments int) *Mesh {
+	return NewMesh(GetRoundedBox(X, Y, Z, radius, segments))
+}
+
+//GetArrowMesh builds an arrow of length standing on the ground along the y axis, a cylinder of
+//shaftRadius with a cone of headLength and headRadius on top.
+func GetArrowMesh(length float32, shaftRadius float32, headLength float32, headRadius float32, vertices int) *Mesh {
+	headLength = math32.Min(headLength, length)
+	arrow := GetCylinderMesh(length-headLength, shaftRadius, shaftRadius, vertices)
+	head := GetCylinderMesh(headLength, headRadius, 0, vertices)
+	head.Positions = Translate(head.Positions, mgl32.Vec3{0, length - headLength, 0})
+	arrow.Append(head)
+	return arrow
+}
+
+//GetAxisMeshes builds the arrows of an axis gizmo from the origin along x, y and z, to be drawn
+//in their own colors.
+func GetAxisMeshes(length float32, radius float32, vertices int) (x, y, z *Mesh) {
+	y = GetArrowMesh(length, radius, 4*radius, 2*radius, vertices)
+	x, z = NewMesh(nil, nil, nil, nil), NewMesh(nil, nil, nil, nil)
+	x.Append(y)
+	z.Append(y)
+	x.Transform(mgl32.HomogRotate3DZ(-math32.Pi / 2))
+	z.Transform(mgl32.HomogRotate3DX(math32.Pi / 2))
+	return
+}
+
+//getGridQuads indexes a (columns+1) x (rows+1) grid stored column by column as two triangles
+//per cell, counter clockwise when the columns grow to the right and the rows grow up.
+func getGridQuads(columns int, rows int) (indices []uint32) {
+	for i := 0; i < columns; i++ {
+		for j := 0; j < rows; j++ {
+			first := uint32(i*(rows+1) + j)
+			next := first + uint32(rows+1)
+			indices = append(indices, first, next, first+1, first+1, next, next+1)
+		}
+	}
+	return
+}
+
+//getGeodesicSphere splits every face of an icosahedron with a vertex on the y axis in
+//frequency x frequency triangles and projects them on the unit sphere.
+func getGeodesicSphere(frequency int) (units []mgl32.Vec3, triangles []uint32) {
+	if frequency < 1 {
+		frequency = 1
+	}
+	phi := (1 + math32.Sqrt(5)) / 2
+	corners := []mgl32.Vec3{
+		{-1, phi, 0}, {1, phi, 0}, {-1, -phi, 0}, {1, -phi, 0},
+		{0, -1, phi}, {0, 1, phi}, {0, -1, -phi}, {0, 1, -phi},
+		{phi, 0, -1}, {phi, 0, 1}, {-phi, 0, -1}, {-phi, 0, 1},
+	}
+	faces := [][3]int{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+	// tilt the icosahedron so corner 0 is the top pole
+	tilt := mgl32.Rotate3DZ(-math32.Atan2(1, phi))
+	for i := range corners {
+		corners[i] = tilt.Mul3x1(corners[i].Normalize())
+	}
+
+	// vertices on the edges are shared by welding their rounded positions
+	welded := map[[3]int32]uint32{}
+	add := func(v mgl32.Vec3) uint32 {
+		v = v.Normalize()
+		key := [3]int32{}
+		for axis := range key {
+			key[axis] = int32(math.Round(float64(v[axis]) * 1e5))
+		}
+		if index, ok := welded[key]; ok {
+			return index
+		}
+		welded[key] = uint32(len(units))
+		units = append(units, v)
+		return welded[key]
+	}
+	for _, face := range faces {
+		a, b, c := corners[face[0]], corners[face[1]], corners[face[2]]
+		// rows of points from a towards the edge b c
+		grid := make([][]uint32, frequency+1)
+		for i := 0; i <= frequency; i++ {
+			for j := 0; j <= i; j++ {
+				p := a
+				if i > 0 {
+					ab := a.Add(b.Sub(a).Mul(float32(i) / float32(frequency)))
+					ac := a.Add(c.Sub(a).Mul(float32(i) / float32(frequency)))
+					p = ab.Add(ac.Sub(ab).Mul(float32(j) / float32(i)))
+				}
+				grid[i] = append(grid[i], add(p))
 			}
 		}
+		for i := 0; i < frequency; i++ {
+			for j := 0; j <= i; j++ {
+				triangles = append(triangles, grid[i][j], grid[i+1][j], grid[i+1][j+1])
+				if j < i {
+					triangles = append(triangles, grid[i][j], grid[i+1][j+1], grid[i][j+1])
+				}
+			}
+		}
+	}
+	return
+}
+
+//getSphereMapping scales the unit sphere triangles by r and maps them by longitude and
+//latitude. Triangles crossing the seam get their own copies of the vertices past it and the
+//poles get a copy per triangle in the middle of its longitudes.
+func getSphereMapping(units []mgl32.Vec3, triangles []uint32, r float32, latitude func(mgl32.Vec3) float32) (vertices []mgl32.Vec3, normals []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) {
+	longitude := func(unit mgl32.Vec3) float32 {
+		u := math32.Atan2(unit.Z(), unit.X()) / (2 * math32.Pi)
+		if u < 0 {
+			u++
+		}
+		return u
+	}
+	isPole := func(unit mgl32.Vec3) bool {
+		return math32.Abs(unit.Y()) > 1-1e-5
+	}
+	for _, unit := range units {
+		vertices = append(vertices, unit.Mul(r))
+		normals = append(normals, unit)
+		tCoords = append(tCoords, mgl32.Vec2{longitude(unit), latitude(unit)})
+	}
+	copies := map[[2]uint32]uint32{}
+	copyVertex := func(index uint32, u float32, key uint32) uint32 {
+		if copied, ok := copies[[2]uint32{index, key}]; ok {
+			return copied
+		}
+		copied := uint32(len(vertices))
+		vertices = append(vertices, vertices[index])
+		normals = append(normals, normals[index])
+		tCoords = append(tCoords, mgl32.Vec2{u, tCoords[index].Y()})
+		copies[[2]uint32{index, key}] = copied
+		return copied
 	}
-	if len(a) == 0 {
-		return append(a1, a2...)
+	for t := 0; t+2 < len(triangles); t += 3 {
+		triangle := []uint32{triangles[t], triangles[t+1], triangles[t+2]}
+		us := []float32{}
+		for _, index := range triangle {
+			if !isPole(units[index]) {
+				us = append(us, tCoords[index].X())
+			}
+		}
+		wraps := false
+		for _, u := range us {
+			wraps = wraps || math32.Abs(u-us[0]) > 0.5
+		}
+		middle := float32(0)
+		for k, u := range us {
+			if wraps && u < 0.5 {
+				u++
+				us[k] = u
+			}
+			middle += u / float32(len(us))
+		}
+		for k, index := range triangle {
+			switch {
+			case isPole(units[index]):
+				triangle[k] = copyVertex(index, middle, uint32(t))
+			case wraps && tCoords[index].X() < 0.5:
+				triangle[k] = copyVertex(index, tCoords[index].X()+1, math.MaxUint32)
+			}
+		}
+		indices = append(indices, triangle...)
 	}
 	return
 }
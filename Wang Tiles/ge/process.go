@@ -0,0 +1,386 @@
+package ge
+
+import (
+	"math"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//NormalWeighting is how ComputeNormals adds the normals of the triangles around a vertex.
+type NormalWeighting int
+
+const (
+	//AreaWeighted lets bigger triangles pull the normal more, like the normals LoadOBJ computes.
+	AreaWeighted NormalWeighting = iota
+	//AngleWeighted weights every triangle by its angle at the vertex, so the normal doesn't
+	//change with how the faces around it are split in triangles.
+	AngleWeighted
+)
+
+//AABB is an axis aligned bounding box.
+type AABB struct {
+	Min, Max mgl32.Vec3
+}
+
+//BoundingSphere is a sphere containing a set of vertices.
+type BoundingSphere struct {
+	Center mgl32.Vec3
+	Radius float32
+}
+
+//WeldVertices3 indexes a triangle list, like the ones GetFlatNormals3 takes, merging the
+//vertices closer than epsilon along every axis.
+func WeldVertices3(vertices []mgl32.Vec3, epsilon float32) (welded []mgl32.Vec3, indices []uint32) {
+	mesh := NewMesh(vertices, nil, nil, nil)
+	for i := range vertices {
+		mesh.Indices = append(mesh.Indices, uint32(i))
+	}
+	mesh.Weld(epsilon)
+	return mesh.Positions, mesh.Indices
+}
+
+//Weld merges the vertices whose positions, normals and uvs are closer than epsilon along every
+//axis, and drops the triangles left without area. Seams of normals and uvs are kept, to merge by
+//position only clear Normals and UVs first and ComputeNormals after.
+func (m *Mesh) Weld(epsilon float32) {
+	epsilon = math32.Max(epsilon, 1e-7)
+	same := func(a, b int) bool {
+		if !isNear(m.Positions[a][:], m.Positions[b][:], epsilon) {
+			return false
+		}
+		if len(m.Normals) == len(m.Positions) && !isNear(m.Normals[a][:], m.Normals[b][:], epsilon) {
+			return false
+		}
+		return len(m.UVs) != len(m.Positions) || isNear(m.UVs[a][:], m.UVs[b][:], epsilon)
+	}
+
+	grid := newVertexGrid(epsilon)
+	remap := make([]uint32, len(m.Positions))
+	welded := NewMesh(nil, nil, nil, nil)
+	for i, position := range m.Positions {
+		candidate, found := grid.find(position, func(candidate int) bool { return same(i, candidate) })
+		grid.add(position, i)
+		if found {
+			remap[i] = remap[candidate]
+			continue
+		}
+		remap[i] = uint32(len(welded.Positions))
+		welded.Positions = append(welded.Positions, position)
+		if len(m.Normals) == len(m.Positions) {
+			welded.Normals = append(welded.Normals, m.Normals[i])
+		}
+		if len(m.UVs) == len(m.Positions) {
+			welded.UVs = append(welded.UVs, m.UVs[i])
+		}
+		if len(m.Tangents) == len(m.Positions) {
+			welded.Tangents = append(welded.Tangents, m.Tangents[i])
+		}
+	}
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := remap[m.Indices[i]], remap[m.Indices[i+1]], remap[m.Indices[i+2]]
+		if a != b && b != c && a != c {
+			welded.Indices = append(welded.Indices, a, b, c)
+		}
+	}
+	m.Positions, m.Normals, m.UVs, m.Tangents, m.Indices = welded.Positions, welded.Normals, welded.UVs, welded.Tangents, welded.Indices
+}
+
+//ComputeNormals replaces the normals with smooth ones, adding the normals of the triangles
+//around every vertex. Copies of a vertex closer than seamEpsilon, like the ones split only by
+//their uvs, share their normal unless the normals they had differ, so hard edges are kept;
+//clear Normals first to smooth them too. Triangles are expected counter clockwise when seen
+//from the front.
+func (m *Mesh) ComputeNormals(weighting NormalWeighting) {
+	hasNormals := len(m.Normals) == len(m.Positions)
+	group := make([]int, len(m.Positions))
+	grid := newVertexGrid(seamEpsilon)
+	for i, position := range m.Positions {
+		group[i] = i
+		first, found := grid.find(position, func(other int) bool {
+			if !isNear(position[:], m.Positions[other][:], seamEpsilon) {
+				return false
+			}
+			return !hasNormals || isNear(m.Normals[i][:], m.Normals[other][:], seamEpsilon)
+		})
+		if found {
+			group[i] = first
+			continue
+		}
+		grid.add(position, i)
+	}
+
+	sums := make([]mgl32.Vec3, len(m.Positions))
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		corners := [3]uint32{m.Indices[i], m.Indices[i+1], m.Indices[i+2]}
+		triangle := [3]mgl32.Vec3{m.Positions[corners[0]], m.Positions[corners[1]], m.Positions[corners[2]]}
+		normal := triangle[1].Sub(triangle[0]).Cross(triangle[2].Sub(triangle[0]))
+		if normal.Len() == 0 {
+			continue
+		}
+		for k, corner := range triangle {
+			weighted := normal
+			if weighting == AngleWeighted {
+				weighted = normal.Normalize().Mul(getCornerAngle(corner, triangle[(k+1)%3], triangle[(k+2)%3]))
+			}
+			sums[group[corners[k]]] = sums[group[corners[k]]].Add(weighted)
+		}
+	}
+	m.Normals = make([]mgl32.Vec3, len(m.Positions))
+	for i := range m.Positions {
+		if sum := sums[group[i]]; sum.Len() > 0 {
+			m.Normals[i] = sum.Normalize()
+		}
+	}
+}
+
+//ComputeTangents fills Tangents from the normals and uvs, like MikkTSpace does for normal maps
+//baked by most tools: the tangents of the triangles around a vertex are added by angle, made
+//perpendicular to its normal, and w is the sign of the bitangent so that
+//bitangent = w * cross(normal, tangent) points along v, as in Terrain.
+func (m *Mesh) ComputeTangents() {
+	if len(m.Normals) != len(m.Positions) || len(m.UVs) != len(m.Positions) {
+		m.Tangents = nil
+		return
+	}
+	tangents := make([]mgl32.Vec3, len(m.Positions))
+	bitangents := make([]mgl32.Vec3, len(m.Positions))
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		corners := [3]uint32{m.Indices[i], m.Indices[i+1], m.Indices[i+2]}
+		p0, p1, p2 := m.Positions[corners[0]], m.Positions[corners[1]], m.Positions[corners[2]]
+		uv0, uv1, uv2 := m.UVs[corners[0]], m.UVs[corners[1]], m.UVs[corners[2]]
+		e1, e2 := p1.Sub(p0), p2.Sub(p0)
+		du1, dv1, du2, dv2 := uv1.X()-uv0.X(), uv1.Y()-uv0.Y(), uv2.X()-uv0.X(), uv2.Y()-uv0.Y()
+		determinant := du1*dv2 - du2*dv1
+		if math32.Abs(determinant) < 1e-12 {
+			continue
+		}
+		tangent := e1.Mul(dv2).Sub(e2.Mul(dv1)).Mul(1 / determinant)
+		bitangent := e2.Mul(du1).Sub(e1.Mul(du2)).Mul(1 / determinant)
+		for k, corner := range corners {
+			p := m.Positions[corner]
+			angle := getCornerAngle(p, m.Positions[corners[(k+1)%3]], m.Positions[corners[(k+2)%3]])
+			// only the direction of the triangle tangents counts, like in MikkTSpace
+			if tangent.Len() > 0 {
+				tangents[corner] = tangents[corner].Add(tangent.Normalize().Mul(angle))
+			}
+			if bitangent.Len() > 0 {
+				bitangents[corner] = bitangents[corner].Add(bitangent.Normalize().Mul(angle))
+			}
+		}
+	}
+
+	m.Tangents = make([]mgl32.Vec4, len(m.Positions))
+	for i, normal := range m.Normals {
+		// Gram-Schmidt against the normal, any direction across it for vertices without one
+		tangent := tangents[i].Sub(normal.Mul(normal.Dot(tangents[i])))
+		if tangent.Len() < 1e-6 {
+			tangent = normal.Cross(mgl32.Vec3{0, 0, 1})
+			if tangent.Len() < 1e-6 {
+				tangent = normal.Cross(mgl32.Vec3{0, 1, 0})
+			}
+		}
+		tangent = tangent.Normalize()
+		w := float32(1)
+		if normal.Cross(tangent).Dot(bitangents[i]) < 0 {
+			w = -1
+		}
+		m.Tangents[i] = tangent.Vec4(w)
+	}
+}
+
+//Transform applies matrix to the mesh. Normals are transformed by the inverse transpose so they
+//stay perpendicular under non uniform scales, and the triangles are turned around when matrix
+//mirrors the mesh so they keep facing out.
+func (m *Mesh) Transform(matrix mgl32.Mat4) {
+	m.Positions = TransformMat4(m.Positions, matrix)
+	linear := matrix.Mat3()
+	normalMatrix := linear.Inv().Transpose()
+	for i, normal := range m.Normals {
+		if normal = normalMatrix.Mul3x1(normal); normal.Len() > 0 {
+			normal = normal.Normalize()
+		}
+		m.Normals[i] = normal
+	}
+	mirrors := linear.Det() < 0
+	for i, tangent := range m.Tangents {
+		direction := linear.Mul3x1(tangent.Vec3())
+		if direction.Len() > 0 {
+			direction = direction.Normalize()
+		}
+		// the bitangent follows the mirror, cross(normal, tangent) doesn't
+		w := tangent.W()
+		if mirrors {
+			w = -w
+		}
+		m.Tangents[i] = direction.Vec4(w)
+	}
+	if mirrors {
+		for i := 0; i+2 < len(m.Indices); i += 3 {
+			m.Indices[i+1], m.Indices[i+2] = m.Indices[i+2], m.Indices[i+1]
+		}
+	}
+}
+
+//Bounds returns the box around the positions of the mesh.
+func (m *Mesh) Bounds() AABB {
+	return GetAABB(m.Positions)
+}
+
+//BoundingSphere returns a sphere around the positions of the mesh.
+func (m *Mesh) BoundingSphere() BoundingSphere {
+	return GetBoundingSphere(m.Positions)
+}
+
+//GetAABB returns the box around vertices, empty at the origin when there are none.
+func GetAABB(vertices []mgl32.Vec3) AABB {
+	if len(vertices) == 0 {
+		return AABB{}
+	}
+	box := AABB{vertices[0], vertices[0]}
+	for _, v := range vertices[1:] {
+		for axis := range v {
+			box.Min[axis] = math32.Min(box.Min[axis], v[axis])
+			box.Max[axis] = math32.Max(box.Max[axis], v[axis])
+		}
+	}
+	return box
+}
+
+//Center returns the middle of the box.
+func (b AABB) Center() mgl32.Vec3 {
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+//Size returns the length of the box along every axis.
+func (b AABB) Size() mgl32.Vec3 {
+	return b.Max.Sub(b.Min)
+}
+
+//Contains tells if point is inside the box or on its faces.
+func (b AABB) Contains(point mgl32.Vec3) bool {
+	for axis := range point {
+		if point[axis] < b.Min[axis] || point[axis] > b.Max[axis] {
+			return false
+		}
+	}
+	return true
+}
+
+//Transform returns the box around the transformed corners of b, to move the bounds of a mesh
+//with its model matrix without going through its vertices.
+func (b AABB) Transform(matrix mgl32.Mat4) AABB {
+	corners := make([]mgl32.Vec3, 0, 8)
+	for i := 0; i < 8; i++ {
+		corner := b.Min
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<uint(axis)) != 0 {
+				corner[axis] = b.Max[axis]
+			}
+		}
+		corners = append(corners, corner)
+	}
+	return GetAABB(TransformMat4(corners, matrix))
+}
+
+//GetBoundingSphere returns a sphere around vertices with Ritter's algorithm, at most about 5%
+//bigger than the smallest one.
+func GetBoundingSphere(vertices []mgl32.Vec3) BoundingSphere {
+	if len(vertices) == 0 {
+		return BoundingSphere{}
+	}
+	farthest := func(from mgl32.Vec3) mgl32.Vec3 {
+		best, distance := from, float32(-1)
+		for _, v := range vertices {
+			if d := v.Sub(from).LenSqr(); d > distance {
+				best, distance = v, d
+			}
+		}
+		return best
+	}
+	// start with the sphere between two points far apart and grow it for the ones outside
+	a := farthest(vertices[0])
+	b := farthest(a)
+	sphere := BoundingSphere{a.Add(b).Mul(0.5), b.Sub(a).Len() / 2}
+	for _, v := range vertices {
+		if distance := v.Sub(sphere.Center).Len(); distance > sphere.Radius {
+			radius := (sphere.Radius + distance) / 2
+			sphere.Center = sphere.Center.Add(v.Sub(sphere.Center).Mul((radius - sphere.Radius) / distance))
+			sphere.Radius = radius
+		}
+	}
+	return sphere
+}
+
+//Transform returns the sphere moved by matrix, its radius scaled by the largest scale of it.
+func (s BoundingSphere) Transform(matrix mgl32.Mat4) BoundingSphere {
+	scale := float32(0)
+	for column := 0; column < 3; column++ {
+		scale = math32.Max(scale, matrix.Col(column).Vec3().Len())
+	}
+	return BoundingSphere{mgl32.TransformCoordinate(s.Center, matrix), s.Radius * scale}
+}
+
+//getCornerAngle is the angle of a triangle at corner, between the edges to a and b.
+func getCornerAngle(corner, a, b mgl32.Vec3) float32 {
+	u, v := a.Sub(corner), b.Sub(corner)
+	if u.Len() == 0 || v.Len() == 0 {
+		return 0
+	}
+	return math32.Acos(math32.Max(-1, math32.Min(1, u.Normalize().Dot(v.Normalize()))))
+}
+
+//seamEpsilon is how close the copies of a vertex on a seam have to be to count as one, cos and
+//sin of 2π aren't exact so they rarely fall on the same position.
+const seamEpsilon = 1e-5
+
+//vertexGrid buckets vertices by position in cells of epsilon, to find the ones closer than
+//epsilon along every axis without comparing them all.
+type vertexGrid struct {
+	epsilon float32
+	cells   map[[3]int64][]int
+}
+
+func newVertexGrid(epsilon float32) *vertexGrid {
+	return &vertexGrid{epsilon: epsilon, cells: map[[3]int64][]int{}}
+}
+
+func (g *vertexGrid) key(v mgl32.Vec3) [3]int64 {
+	return [3]int64{
+		int64(math.Floor(float64(v.X() / g.epsilon))),
+		int64(math.Floor(float64(v.Y() / g.epsilon))),
+		int64(math.Floor(float64(v.Z() / g.epsilon))),
+	}
+}
+
+func (g *vertexGrid) add(position mgl32.Vec3, vertex int) {
+	cell := g.key(position)
+	g.cells[cell] = append(g.cells[cell], vertex)
+}
+
+//find returns the first vertex added near position that match accepts. Neighbour cells are
+//searched too for the vertices that fall on both sides of a cell border.
+func (g *vertexGrid) find(position mgl32.Vec3, match func(vertex int) bool) (int, bool) {
+	cell := g.key(position)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				for _, vertex := range g.cells[[3]int64{cell[0] + dx, cell[1] + dy, cell[2] + dz}] {
+					if match(vertex) {
+						return vertex, true
+					}
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func isNear(a, b []float32, epsilon float32) bool {
+	for i := range a {
+		if math32.Abs(a[i]-b[i]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package ge
+
+import (
+	"testing"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestWeldVertices3(t *testing.T) {
+	// a quad as a triangle list, the shared corners are a little off like after rounding
+	quad := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 0}, {1, 0, 1 + 1e-7}, {0, 0, 1}}
+	welded, indices := WeldVertices3(quad, 1e-5)
+	if len(welded) != 4 || len(indices) != 6 {
+		t.Fatalf("got %d vertices and %d indices, want 4 and 6", len(welded), len(indices))
+	}
+	if indices[3] != indices[0] || indices[4] != indices[2] {
+		t.Errorf("shared corners not welded: %v", indices)
+	}
+}
+
+func TestWeldKeepsSeams(t *testing.T) {
+	mesh := NewMesh(
+		[]mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}, {1, 0, 0}},
+		nil,
+		[]mgl32.Vec2{{0, 0}, {1, 0}, {0, 1}, {1, 0}, {1, 1}, {0, 1}, {0, 0}},
+		// the last triangle collapses once its repeated corner is welded
+		[]uint32{0, 2, 1, 3, 5, 4, 1, 6, 3},
+	)
+	mesh.Weld(1e-5)
+	if len(mesh.Positions) != 5 || len(mesh.UVs) != 5 {
+		t.Errorf("got %d vertices, want 5 with the uv seam kept", len(mesh.Positions))
+	}
+	if len(mesh.Indices) != 6 {
+		t.Errorf("got %d indices, want 6 without the collapsed triangle", len(mesh.Indices))
+	}
+}
+
+func TestComputeNormalsKeepsHardEdges(t *testing.T) {
+	box := GetCubicHexahedronMesh(2, 2, 2)
+	flat := append([]mgl32.Vec3{}, box.Normals...)
+	box.ComputeNormals(AreaWeighted)
+	for i, normal := range box.Normals {
+		if normal.Sub(flat[i]).Len() > 1e-5 {
+			t.Fatalf("normal %d is %v, want the face normal %v", i, normal, flat[i])
+		}
+	}
+	box.Normals = nil
+	box.ComputeNormals(AngleWeighted)
+	for i, normal := range box.Normals {
+		// every corner is shared by three faces
+		if math32.Abs(math32.Abs(normal.X())-math32.Sqrt(1./3)) > 1e-4 {
+			t.Fatalf("normal %d is %v, want the corner diagonal", i, normal)
+		}
+	}
+}
+
+func TestTransformMirrorKeepsWinding(t *testing.T) {
+	sphere := GetSphereMesh(1, 16)
+	before := getSignedVolume(sphere)
+	sphere.Transform(mgl32.Scale3D(-1, 1, 1))
+	if after := getSignedVolume(sphere); after <= 0 || math32.Abs(after-before) > 1e-4 {
+		t.Errorf("signed volume %v after mirroring, want %v", after, before)
+	}
+	for i := 0; i+2 < len(sphere.Indices); i += 3 {
+		a, b, c := sphere.Positions[sphere.Indices[i]], sphere.Positions[sphere.Indices[i+1]], sphere.Positions[sphere.Indices[i+2]]
+		face := b.Sub(a).Cross(c.Sub(a))
+		if face.Len() > 1e-6 && face.Dot(sphere.Normals[sphere.Indices[i]]) <= 0 {
+			t.Fatalf("triangle %d faces against its normals", i/3)
+		}
+	}
+}
+
+func TestGetAABB(t *testing.T) {
+	box := GetAABB([]mgl32.Vec3{{1, -2, 3}, {-1, 4, 0}, {0, 0, -5}})
+	if box.Min != (mgl32.Vec3{-1, -2, -5}) || box.Max != (mgl32.Vec3{1, 4, 3}) {
+		t.Errorf("got %v", box)
+	}
+	if box.Center() != (mgl32.Vec3{0, 1, -1}) || box.Size() != (mgl32.Vec3{2, 6, 8}) {
+		t.Errorf("got center %v and size %v", box.Center(), box.Size())
+	}
+}
+
+func TestGetBoundingSphere(t *testing.T) {
+	sphere := GetBoundingSphere([]mgl32.Vec3{{-2, 0, 0}, {2, 0, 0}, {0, 1, 0}, {0, -1, .5}})
+	if sphere.Center.Len() > 1e-5 || math32.Abs(sphere.Radius-2) > 1e-5 {
+		t.Errorf("got %v, want radius 2 around the origin", sphere)
+	}
+	points := GetSphereMesh(3, 16).Positions
+	sphere = GetBoundingSphere(points)
+	for _, point := range points {
+		if point.Sub(sphere.Center).Len() > sphere.Radius+1e-4 {
+			t.Fatalf("%v is outside %v", point, sphere)
+		}
+	}
+	if sphere.Radius > 3*1.05 {
+		t.Errorf("radius %v, want about 3", sphere.Radius)
+	}
+}
+
+//getSignedVolume is the volume of a closed mesh, negative when it faces inwards.
+func getSignedVolume(m *Mesh) (volume float32) {
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := m.Positions[m.Indices[i]], m.Positions[m.Indices[i+1]], m.Positions[m.Indices[i+2]]
+		volume += a.Dot(b.Cross(c)) / 6
+	}
+	return
+}
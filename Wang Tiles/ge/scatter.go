@@ -0,0 +1,162 @@
+package ge
+
+import (
+	"math/rand"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//Scatter are the rules ScatterPoints places objects with, over a rectangle of the xz plane.
+type Scatter struct {
+	// Min and Max are the corners of the region, x and z
+	Min, Max mgl32.Vec2
+	// Spacing is the smallest distance between two objects
+	Spacing float32
+	// Density, if set, is the chance of keeping an object, from 0 to 1, at texture coordinates
+	// u, v stretched over the region like GetImageHeight, which makes a density map out of an
+	// image with GetImageHeight(img, 1)
+	Density func(u, v float32) float32
+	// Exclude are zones left empty, like paths or clearings
+	Exclude []ScatterZone
+	// Terrain, if set, lifts objects onto its surface and MaxSlope, in radians, drops the ones
+	// on steeper ground, 0 keeps them all
+	Terrain  *Terrain
+	MaxSlope float32
+	// MinScale and MaxScale bound the random scale of every object, 0 for both is 1
+	MinScale, MaxScale float32
+}
+
+//ScatterZone tells if x, z is inside a zone.
+type ScatterZone func(x, z float32) bool
+
+//ScatterPoint is where an object goes, turned by Rotation radians around y and scaled evenly.
+type ScatterPoint struct {
+	Position mgl32.Vec3
+	Rotation float32
+	Scale    float32
+}
+
+//Transform returns the model matrix of the object, scaled, turned and then moved.
+func (p ScatterPoint) Transform() mgl32.Mat4 {
+	return mgl32.Translate3D(p.Position.X(), p.Position.Y(), p.Position.Z()).Mul4(mgl32.HomogRotate3DY(p.Rotation)).Mul4(mgl32.Scale3D(p.Scale, p.Scale, p.Scale))
+}
+
+//RectZone is the rectangle between min and max, use math32.Inf for zones that cross the
+//whole region.
+func RectZone(min, max mgl32.Vec2) ScatterZone {
+	return func(x, z float32) bool {
+		return x >= min.X() && x <= max.X() && z >= min.Y() && z <= max.Y()
+	}
+}
+
+//CircleZone is the circle of radius r around center.
+func CircleZone(center mgl32.Vec2, r float32) ScatterZone {
+	return func(x, z float32) bool {
+		return mgl32.Vec2{x, z}.Sub(center).LenSqr() <= r*r
+	}
+}
+
+//ScatterPoints places objects by the rules of scatter with Poisson disk sampling, no two closer
+//than Spacing and without the clumps and gaps of random points. The Density, Exclude and
+//MaxSlope rules then drop some of them, every object keeps its own random values so changing
+//them doesn't move the others. The same seed always places the same objects.
+func ScatterPoints(scatter Scatter, seed int64) (points []ScatterPoint) {
+	random := rand.New(rand.NewSource(seed))
+	size := scatter.Max.Sub(scatter.Min)
+	minScale, maxScale := scatter.MinScale, scatter.MaxScale
+	if minScale == 0 && maxScale == 0 {
+		minScale, maxScale = 1, 1
+	}
+	for _, sample := range getPoissonDisk(scatter.Min, scatter.Max, scatter.Spacing, random) {
+		keep, rotation := random.Float32(), random.Float32()*2*math32.Pi
+		scale := minScale + random.Float32()*(maxScale-minScale)
+		x, z := sample.X(), sample.Y()
+		if scatter.Density != nil && keep >= scatter.Density((x-scatter.Min.X())/size.X(), (z-scatter.Min.Y())/size.Y()) {
+			continue
+		}
+		if isInScatterZone(scatter.Exclude, x, z) {
+			continue
+		}
+		y := float32(0)
+		if scatter.Terrain != nil {
+			if scatter.MaxSlope > 0 && scatter.Terrain.Normal(x, z).Y() < math32.Cos(scatter.MaxSlope) {
+				continue
+			}
+			y = scatter.Terrain.Height(x, z)
+		}
+		points = append(points, ScatterPoint{Position: mgl32.Vec3{x, y, z}, Rotation: rotation, Scale: scale})
+	}
+	return
+}
+
+func isInScatterZone(zones []ScatterZone, x, z float32) bool {
+	for _, zone := range zones {
+		if zone(x, z) {
+			return true
+		}
+	}
+	return false
+}
+
+//getPoissonDisk fills the rectangle between min and max with points at least spacing apart,
+//with Bridson's algorithm: new points are tried around the active ones until none fits.
+func getPoissonDisk(min, max mgl32.Vec2, spacing float32, random *rand.Rand) (samples []mgl32.Vec2) {
+	const attempts = 30
+	size := max.Sub(min)
+	if spacing <= 0 || size.X() < 0 || size.Y() < 0 {
+		return nil
+	}
+	// a cell is small enough to hold one point at most
+	cellSize := spacing / math32.Sqrt(2)
+	columns, rows := int(size.X()/cellSize)+1, int(size.Y()/cellSize)+1
+	grid := make([]int, columns*rows)
+	cell := func(p mgl32.Vec2) (int, int) {
+		offset := p.Sub(min)
+		return minInt(int(offset.X()/cellSize), columns-1), minInt(int(offset.Y()/cellSize), rows-1)
+	}
+	add := func(p mgl32.Vec2) {
+		samples = append(samples, p)
+		c, r := cell(p)
+		grid[c+columns*r] = len(samples)
+	}
+	fits := func(p mgl32.Vec2) bool {
+		if p.X() < min.X() || p.X() > max.X() || p.Y() < min.Y() || p.Y() > max.Y() {
+			return false
+		}
+		c, r := cell(p)
+		for j := maxInt(r-2, 0); j <= minInt(r+2, rows-1); j++ {
+			for i := maxInt(c-2, 0); i <= minInt(c+2, columns-1); i++ {
+				if n := grid[i+columns*j]; n > 0 && samples[n-1].Sub(p).LenSqr() < spacing*spacing {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	add(mgl32.Vec2{min.X() + random.Float32()*size.X(), min.Y() + random.Float32()*size.Y()})
+	active := []int{0}
+	for len(active) > 0 {
+		k := random.Intn(len(active))
+		center := samples[active[k]]
+		found := false
+		for attempt := 0; attempt < attempts; attempt++ {
+			// uniform over the ring between spacing and twice spacing
+			angle := random.Float32() * 2 * math32.Pi
+			r := spacing * math32.Sqrt(1+3*random.Float32())
+			p := center.Add(mgl32.Vec2{math32.Cos(angle), math32.Sin(angle)}.Mul(r))
+			if fits(p) {
+				add(p)
+				active = append(active, len(samples)-1)
+				found = true
+				break
+			}
+		}
+		if !found {
+			active[k] = active[len(active)-1]
+			active = active[:len(active)-1]
+		}
+	}
+	return
+}
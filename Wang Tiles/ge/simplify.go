@@ -0,0 +1,368 @@
+package ge
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//Simplify returns a copy of the mesh with at most targetTriangles triangles, collapsing the
+//edges that change the surface the least by the quadric error metric of Garland and Heckbert.
+//Vertices closer than seamEpsilon move together so uv and normal seams don't open, and both
+//seams and open borders are kept from moving off their lines. Normals and uvs are interpolated
+//along the collapsed edges away from seams, tangents are dropped.
+func (m *Mesh) Simplify(targetTriangles int) *Mesh {
+	s := newSimplifier(m)
+	s.run(targetTriangles)
+	return s.result()
+}
+
+//quadric is a symmetric 4x4 matrix, the sum of the squared distances to a set of planes.
+type quadric [10]float64
+
+func newPlaneQuadric(normal mgl32.Vec3, point mgl32.Vec3, weight float64) (q quadric) {
+	a, b, c := float64(normal.X()), float64(normal.Y()), float64(normal.Z())
+	d := -(a*float64(point.X()) + b*float64(point.Y()) + c*float64(point.Z()))
+	return quadric{
+		a * a * weight, a * b * weight, a * c * weight, a * d * weight,
+		b * b * weight, b * c * weight, b * d * weight,
+		c * c * weight, c * d * weight,
+		d * d * weight,
+	}
+}
+
+func (q quadric) add(other quadric) quadric {
+	for i := range q {
+		q[i] += other[i]
+	}
+	return q
+}
+
+//distance is the sum of the squared distances of v to the planes of q.
+func (q quadric) distance(v mgl32.Vec3) float64 {
+	x, y, z := float64(v.X()), float64(v.Y()), float64(v.Z())
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z +
+		q[9]
+}
+
+//optimum is the point of least error, false when the planes don't pin down a single point.
+func (q quadric) optimum() (mgl32.Vec3, bool) {
+	a := mgl32.Mat3{
+		float32(q[0]), float32(q[1]), float32(q[2]),
+		float32(q[1]), float32(q[4]), float32(q[5]),
+		float32(q[2]), float32(q[5]), float32(q[7]),
+	}
+	if det := a.Det(); math.Abs(float64(det)) < 1e-9 {
+		return mgl32.Vec3{}, false
+	}
+	return a.Inv().Mul3x1(mgl32.Vec3{-float32(q[3]), -float32(q[6]), -float32(q[8])}), true
+}
+
+type collapse struct {
+	cost     float64
+	a, b     int
+	versions [2]int
+	position mgl32.Vec3
+	// t places the position along the edge from a to b, to interpolate the attributes
+	t float32
+}
+
+type collapseHeap []collapse
+
+func (h collapseHeap) Len() int            { return len(h) }
+func (h collapseHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h collapseHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *collapseHeap) Push(x interface{}) { *h = append(*h, x.(collapse)) }
+func (h *collapseHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+//simplifier collapses edges between groups of vertices with the same position, the triangles
+//keep pointing to vertices so their normals and uvs survive.
+type simplifier struct {
+	normals   []mgl32.Vec3
+	uvs       []mgl32.Vec2
+	triangles [][3]int
+	removed   []bool
+	alive     int
+	group     []int
+
+	// per group
+	positions []mgl32.Vec3
+	vertices  [][]int
+	quadrics  []quadric
+	faces     [][]int
+	versions  []int
+	collapsed []bool
+	queue     collapseHeap
+}
+
+func newSimplifier(m *Mesh) *simplifier {
+	// vertices repeated with the same attributes, like the ones of strips, are one vertex
+	welded := NewMesh(m.Positions, m.Normals, m.UVs, m.Indices)
+	welded.Weld(seamEpsilon)
+	s := &simplifier{group: make([]int, len(welded.Positions))}
+	if len(welded.Normals) == len(welded.Positions) {
+		s.normals = welded.Normals
+	}
+	if len(welded.UVs) == len(welded.Positions) {
+		s.uvs = welded.UVs
+	}
+	// the copies of a vertex on a seam are grouped like Weld merges them
+	grid := newVertexGrid(seamEpsilon)
+	for v, p := range welded.Positions {
+		first, ok := grid.find(p, func(other int) bool { return isNear(p[:], welded.Positions[other][:], seamEpsilon) })
+		g := s.group[first]
+		if !ok {
+			g = len(s.positions)
+			grid.add(p, v)
+			s.positions = append(s.positions, p)
+			s.vertices = append(s.vertices, nil)
+		}
+		s.group[v] = g
+		s.vertices[g] = append(s.vertices[g], v)
+	}
+	s.quadrics = make([]quadric, len(s.positions))
+	s.faces = make([][]int, len(s.positions))
+	s.versions = make([]int, len(s.positions))
+	s.collapsed = make([]bool, len(s.positions))
+
+	// edges are counted by position, to find the borders, and by vertex, to find the seams
+	edges, vertexEdges := map[[2]int]int{}, map[[2]int]int{}
+	for i := 0; i+2 < len(welded.Indices); i += 3 {
+		triangle := [3]int{int(welded.Indices[i]), int(welded.Indices[i+1]), int(welded.Indices[i+2])}
+		groups := s.groups(triangle)
+		p0, p1, p2 := s.positions[groups[0]], s.positions[groups[1]], s.positions[groups[2]]
+		normal := p1.Sub(p0).Cross(p2.Sub(p0))
+		if normal.Len() == 0 {
+			continue
+		}
+		face := len(s.triangles)
+		s.triangles = append(s.triangles, triangle)
+		plane := newPlaneQuadric(normal.Normalize(), p0, float64(normal.Len()/2))
+		for k, g := range groups {
+			s.quadrics[g] = s.quadrics[g].add(plane)
+			s.faces[g] = append(s.faces[g], face)
+			edges[getEdgeKey(g, groups[(k+1)%3])]++
+			vertexEdges[getEdgeKey(triangle[k], triangle[(k+1)%3])]++
+		}
+	}
+	s.removed = make([]bool, len(s.triangles))
+	s.alive = len(s.triangles)
+
+	// borders and seams get a steep plane across them so they stay on their lines
+	for _, triangle := range s.triangles {
+		groups := s.groups(triangle)
+		p0, p1, p2 := s.positions[groups[0]], s.positions[groups[1]], s.positions[groups[2]]
+		normal := p1.Sub(p0).Cross(p2.Sub(p0)).Normalize()
+		for k := range triangle {
+			if vertexEdges[getEdgeKey(triangle[k], triangle[(k+1)%3])] != 1 {
+				continue
+			}
+			a, b := groups[k], groups[(k+1)%3]
+			edge := s.positions[b].Sub(s.positions[a])
+			plane := newPlaneQuadric(edge.Cross(normal).Normalize(), s.positions[a], 1e3*float64(edge.LenSqr()))
+			s.quadrics[a] = s.quadrics[a].add(plane)
+			s.quadrics[b] = s.quadrics[b].add(plane)
+		}
+	}
+	for edge := range edges {
+		s.push(edge[0], edge[1])
+	}
+	return s
+}
+
+func getEdgeKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+func (s *simplifier) groups(triangle [3]int) [3]int {
+	return [3]int{s.group[triangle[0]], s.group[triangle[1]], s.group[triangle[2]]}
+}
+
+//push queues the collapse of the edge between groups a and b at its best position. A group on
+//a seam only takes in groups that aren't, keeping its position.
+func (s *simplifier) push(a, b int) {
+	seamA, seamB := len(s.vertices[a]) > 1, len(s.vertices[b]) > 1
+	if seamB && !seamA {
+		a, b, seamA, seamB = b, a, seamB, seamA
+	}
+	q := s.quadrics[a].add(s.quadrics[b])
+	pa, pb := s.positions[a], s.positions[b]
+	candidates := []mgl32.Vec3{pa}
+	if seamA == seamB {
+		candidates = append(candidates, pb, pa.Add(pb).Mul(0.5))
+		if optimum, ok := q.optimum(); ok {
+			candidates = append(candidates, optimum)
+		}
+	}
+	best := collapse{cost: math.Inf(1), a: a, b: b, versions: [2]int{s.versions[a], s.versions[b]}}
+	for _, candidate := range candidates {
+		if cost := q.distance(candidate); cost < best.cost {
+			best.cost, best.position = cost, candidate
+		}
+	}
+	edge := pb.Sub(pa)
+	if length := edge.LenSqr(); length > 0 {
+		best.t = mgl32.Clamp(best.position.Sub(pa).Dot(edge)/length, 0, 1)
+	}
+	heap.Push(&s.queue, best)
+}
+
+func (s *simplifier) run(targetTriangles int) {
+	for s.alive > targetTriangles && s.queue.Len() > 0 {
+		c := heap.Pop(&s.queue).(collapse)
+		if s.collapsed[c.a] || s.collapsed[c.b] || s.versions[c.a] != c.versions[0] || s.versions[c.b] != c.versions[1] {
+			continue
+		}
+		if s.flips(c) {
+			continue
+		}
+		s.apply(c)
+	}
+}
+
+//flips tells if moving the groups of c turns any of the triangles left around.
+func (s *simplifier) flips(c collapse) bool {
+	for _, g := range []int{c.a, c.b} {
+		for _, face := range s.faces[g] {
+			if s.removed[face] {
+				continue
+			}
+			groups := s.groups(s.triangles[face])
+			var before, after [3]mgl32.Vec3
+			touched := 0
+			for k, corner := range groups {
+				before[k], after[k] = s.positions[corner], s.positions[corner]
+				if corner == c.a || corner == c.b {
+					after[k] = c.position
+					touched++
+				}
+			}
+			if touched > 1 {
+				continue
+			}
+			oldNormal := before[1].Sub(before[0]).Cross(before[2].Sub(before[0]))
+			newNormal := after[1].Sub(after[0]).Cross(after[2].Sub(after[0]))
+			if newNormal.Dot(oldNormal) <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//apply moves group a to the position of c, merges b into it and queues the edges around a
+//again. Every vertex of b is replaced by the vertex of a with the closest attributes.
+func (s *simplifier) apply(c collapse) {
+	a, b := c.a, c.b
+	s.positions[a] = c.position
+	replace := map[int]int{}
+	for _, vb := range s.vertices[b] {
+		closest, distance := s.vertices[a][0], float32(math.MaxFloat32)
+		for _, va := range s.vertices[a] {
+			if d := s.attributeDistance(va, vb); d < distance {
+				closest, distance = va, d
+			}
+		}
+		replace[vb] = closest
+	}
+	if len(s.vertices[a]) == 1 && len(s.vertices[b]) == 1 {
+		va, vb := s.vertices[a][0], s.vertices[b][0]
+		if s.normals != nil {
+			if normal := s.normals[va].Mul(1 - c.t).Add(s.normals[vb].Mul(c.t)); normal.Len() > 0 {
+				s.normals[va] = normal.Normalize()
+			}
+		}
+		if s.uvs != nil {
+			s.uvs[va] = s.uvs[va].Mul(1 - c.t).Add(s.uvs[vb].Mul(c.t))
+		}
+	}
+	s.quadrics[a] = s.quadrics[a].add(s.quadrics[b])
+	s.collapsed[b] = true
+	s.versions[a]++
+
+	for _, face := range s.faces[b] {
+		if s.removed[face] {
+			continue
+		}
+		triangle := &s.triangles[face]
+		groups := s.groups(*triangle)
+		if groups[0] == a || groups[1] == a || groups[2] == a {
+			s.removed[face] = true
+			s.alive--
+			continue
+		}
+		for k, v := range triangle {
+			if replacement, ok := replace[v]; ok {
+				triangle[k] = replacement
+			}
+		}
+		s.faces[a] = append(s.faces[a], face)
+	}
+	s.faces[b] = nil
+
+	neighbours := map[int]bool{}
+	faces := s.faces[a][:0]
+	for _, face := range s.faces[a] {
+		if s.removed[face] {
+			continue
+		}
+		faces = append(faces, face)
+		for _, g := range s.groups(s.triangles[face]) {
+			if g != a {
+				neighbours[g] = true
+			}
+		}
+	}
+	s.faces[a] = faces
+	for g := range neighbours {
+		s.push(a, g)
+	}
+}
+
+func (s *simplifier) attributeDistance(a, b int) (distance float32) {
+	if s.normals != nil {
+		distance += s.normals[a].Sub(s.normals[b]).Len()
+	}
+	if s.uvs != nil {
+		distance += s.uvs[a].Sub(s.uvs[b]).Len()
+	}
+	return
+}
+
+//result builds a mesh from the triangles left, keeping only the vertices they use.
+func (s *simplifier) result() *Mesh {
+	simplified := NewMesh(nil, nil, nil, nil)
+	remap := map[int]uint32{}
+	for face, triangle := range s.triangles {
+		if s.removed[face] {
+			continue
+		}
+		for _, v := range triangle {
+			index, ok := remap[v]
+			if !ok {
+				index = uint32(len(simplified.Positions))
+				remap[v] = index
+				simplified.Positions = append(simplified.Positions, s.positions[s.group[v]])
+				if s.normals != nil {
+					simplified.Normals = append(simplified.Normals, s.normals[v])
+				}
+				if s.uvs != nil {
+					simplified.UVs = append(simplified.UVs, s.uvs[v])
+				}
+			}
+			simplified.Indices = append(simplified.Indices, index)
+		}
+	}
+	return simplified
+}
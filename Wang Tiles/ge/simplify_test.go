@@ -0,0 +1,51 @@
+package ge
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//countOpenEdges counts the edges not matched by the same edge the other way round on another
+//triangle, comparing positions rounded to 1e-4 so seam copies count as one vertex.
+func countOpenEdges(m *Mesh) (open int) {
+	round := func(v mgl32.Vec3) [3]int64 {
+		return [3]int64{int64(math.Round(float64(v.X()) * 1e4)), int64(math.Round(float64(v.Y()) * 1e4)), int64(math.Round(float64(v.Z()) * 1e4))}
+	}
+	edges := map[[2][3]int64]int{}
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		for k := 0; k < 3; k++ {
+			edges[[2][3]int64{round(m.Positions[m.Indices[i+k]]), round(m.Positions[m.Indices[i+(k+1)%3]])}]++
+		}
+	}
+	for edge, count := range edges {
+		if edges[[2][3]int64{edge[1], edge[0]}] != count {
+			open++
+		}
+	}
+	return
+}
+
+func TestSimplifyKeepsSeamsClosed(t *testing.T) {
+	tests := []struct {
+		name      string
+		mesh      *Mesh
+		triangles int
+	}{
+		{"sphere", GetSphereMesh(1, 24), 108},
+		{"torus", GetTorusMesh(1, .3, 24, 12), 144},
+	}
+	for _, test := range tests {
+		if open := countOpenEdges(test.mesh); open != 0 {
+			t.Fatalf("%s has %d open edges before simplifying", test.name, open)
+		}
+		simplified := test.mesh.Simplify(test.triangles)
+		if count := len(simplified.Indices) / 3; count > test.triangles || count == 0 {
+			t.Errorf("%s simplified to %d triangles, want at most %d", test.name, count, test.triangles)
+		}
+		if open := countOpenEdges(simplified); open != 0 {
+			t.Errorf("%s simplified has %d open edges", test.name, open)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package ge
+
+import (
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//GetLatheMesh revolves profile around the y axis in segments steps, its points are (radius,
+//height) pairs from the bottom up so the faces look outwards. Repeating a point of the profile
+//makes a hard edge, like the rim of a well, and repeating the first point at the end closes it,
+//like a tire. u goes around the axis like GetCylinderTextureCoords and v along the profile.
+func GetLatheMesh(profile []mgl32.Vec2, segments int) *Mesh {
+	profileNormals, profileV := getProfileNormals(profile), getPolylineV2(profile)
+	rings := make([][]mgl32.Vec3, len(profile))
+	normals := make([][]mgl32.Vec3, len(profile))
+	uvs := make([][]mgl32.Vec2, len(profile))
+	for i, point := range profile {
+		for j := 0; j <= segments; j++ {
+			angle := 2 * math32.Pi * float32(j) / float32(segments)
+			direction := mgl32.Vec3{math32.Cos(angle), 0, math32.Sin(angle)}
+			rings[i] = append(rings[i], direction.Mul(point.X()).Add(mgl32.Vec3{0, point.Y(), 0}))
+			normals[i] = append(normals[i], direction.Mul(profileNormals[i].X()).Add(mgl32.Vec3{0, profileNormals[i].Y(), 0}))
+			uvs[i] = append(uvs[i], mgl32.Vec2{float32(j) / float32(segments), profileV[i]})
+		}
+	}
+	return getLoftMesh(rings, normals, uvs)
+}
+
+//GetExtrudeMesh extrudes polygon, its points are (x, z) pairs on the ground, along direction.
+//The sides are flat, u goes around the polygon and v along direction. The caps are
+//triangulated like the faces of LoadOBJ, so the polygon may be concave, and are mapped to the
+//unit square by the bounds of the polygon. Polygons of less than 3 points give an empty mesh.
+func GetExtrudeMesh(polygon []mgl32.Vec2, direction mgl32.Vec3, caps bool) *Mesh {
+	mesh := NewMesh(nil, nil, nil, nil)
+	if len(polygon) < 3 {
+		return mesh
+	}
+	bottom := make([]mgl32.Vec3, len(polygon))
+	for i, point := range polygon {
+		bottom[i] = mgl32.Vec3{point.X(), 0, point.Y()}
+	}
+	top := Translate(bottom, direction)
+	// winding of the polygon seen from above, to point the sides outwards
+	winding := getPolygonNormal(bottom)
+	perimeter := getPolylineV3(append(bottom, bottom[0]))
+	for i := range bottom {
+		next := (i + 1) % len(bottom)
+		normal := bottom[next].Sub(bottom[i]).Cross(direction)
+		if normal.Len() == 0 {
+			continue
+		}
+		normal = normal.Normalize()
+		if normal.Dot(bottom[next].Sub(bottom[i]).Cross(winding)) < 0 {
+			normal = normal.Mul(-1)
+		}
+		mesh.AppendStrip(
+			[]mgl32.Vec3{bottom[i], top[i], bottom[next], top[next]},
+			GetConstantNormals3(normal, 4),
+			[]mgl32.Vec2{{perimeter[i], 0}, {perimeter[i], 1}, {perimeter[i+1], 0}, {perimeter[i+1], 1}},
+		)
+	}
+	if !caps {
+		return mesh
+	}
+
+	min, max := polygon[0], polygon[0]
+	for _, point := range polygon {
+		min = mgl32.Vec2{math32.Min(min.X(), point.X()), math32.Min(min.Y(), point.Y())}
+		max = mgl32.Vec2{math32.Max(max.X(), point.X()), math32.Max(max.Y(), point.Y())}
+	}
+	size := max.Sub(min)
+	uvs := make([]mgl32.Vec2, len(polygon))
+	for i, point := range polygon {
+		uvs[i] = mgl32.Vec2{(point.X() - min.X()) / math32.Max(size.X(), 1e-6), (point.Y() - min.Y()) / math32.Max(size.Y(), 1e-6)}
+	}
+	up := mgl32.Vec3{0, 1, 0}
+	if direction.Y() < 0 {
+		up = up.Mul(-1)
+	}
+	triangles := triangulatePolygon(bottom, winding)
+	for _, face := range []struct {
+		vertices []mgl32.Vec3
+		normal   mgl32.Vec3
+	}{{bottom, up.Mul(-1)}, {top, up}} {
+		first := uint32(len(mesh.Positions))
+		mesh.appendVertices(face.vertices, GetConstantNormals3(face.normal, len(face.vertices)), uvs)
+		for _, triangle := range triangles {
+			mesh.appendTriangle(first+uint32(triangle[0]), first+uint32(triangle[1]), first+uint32(triangle[2]))
+		}
+	}
+	return mesh
+}
+
+//GetSweepMesh moves profile along path, a polyline, on the rotation minimizing frames of
+//GetParallelFrames: the x of the profile follows the normal, which starts towards the inside
+//of the curve, and its y the binormal, and the profile doesn't twist around the path as it
+//turns. Profiles are given like the ones of GetLatheMesh, counter clockwise to look outwards.
+//u goes along the profile and v along the path.
+func GetSweepMesh(profile []mgl32.Vec2, path []mgl32.Vec3) *Mesh {
+	tangents, normals, binormals := GetParallelFrames(path)
+	return getFramedSweepMesh(profile, path, tangents, normals, binormals)
+}
+
+//GetFrenetSweepMesh is GetSweepMesh on the Frenet frames of path, the x of the profile always
+//points towards the inside of the curve, like the banking of a track, and twists with it.
+func GetFrenetSweepMesh(profile []mgl32.Vec2, path []mgl32.Vec3) *Mesh {
+	tangents, normals, binormals := GetFrenetFrames(path)
+	return getFramedSweepMesh(profile, path, tangents, normals, binormals)
+}
+
+func getFramedSweepMesh(profile []mgl32.Vec2, path []mgl32.Vec3, tangents, frameNormals, binormals []mgl32.Vec3) *Mesh {
+	profileNormals, profileU := getProfileNormals(profile), getPolylineV2(profile)
+	pathV := getPolylineV3(path)
+	rings := make([][]mgl32.Vec3, len(path))
+	normals := make([][]mgl32.Vec3, len(path))
+	uvs := make([][]mgl32.Vec2, len(path))
+	for i, center := range path {
+		for j, point := range profile {
+			rings[i] = append(rings[i], center.Add(frameNormals[i].Mul(point.X())).Add(binormals[i].Mul(point.Y())))
+			normal := frameNormals[i].Mul(profileNormals[j].X()).Add(binormals[i].Mul(profileNormals[j].Y()))
+			normals[i] = append(normals[i], normal.Sub(tangents[i].Mul(normal.Dot(tangents[i]))).Normalize())
+			uvs[i] = append(uvs[i], mgl32.Vec2{profileU[j], pathV[i]})
+		}
+	}
+	return getLoftMesh(rings, normals, uvs)
+}
+
+//GetBezierSweepMesh is GetSweepMesh along the bezier curve of controlPoints, sampled like
+//mgl32.MakeBezierCurve3D in segments steps.
+func GetBezierSweepMesh(profile []mgl32.Vec2, controlPoints []mgl32.Vec3, segments int) *Mesh {
+	return GetSweepMesh(profile, mgl32.MakeBezierCurve3D(segments+1, controlPoints))
+}
+
+//GetTubeMesh sweeps a circle of radius r with vertices sides along path, open at the ends.
+func GetTubeMesh(path []mgl32.Vec3, r float32, vertices int) *Mesh {
+	profile := []mgl32.Vec2{}
+	for _, v := range GetCircleVertices3(r, vertices)[1:] {
+		profile = append(profile, mgl32.Vec2{v.X(), v.Z()})
+	}
+	return GetSweepMesh(profile, path)
+}
+
+//GetFrenetFrames returns the unit tangent, normal and binormal of every point of path. The
+//normal points towards the inside of the curve, where the path is straight the frame of the
+//previous point is carried on. Only half turns, where the curve bends the other way, are
+//avoided: the frame still twists around the tangent as fast as the plane the path curves in
+//turns, use GetParallelFrames for frames that don't.
+func GetFrenetFrames(path []mgl32.Vec3) (tangents, normals, binormals []mgl32.Vec3) {
+	tangents = getPathTangents(path)
+	normals = make([]mgl32.Vec3, len(path))
+	binormals = make([]mgl32.Vec3, len(path))
+	normal := getStartNormal(path, tangents)
+	for i, tangent := range tangents {
+		if curvature := getCurvature(path, tangents, i); curvature.Len() > 0 {
+			if curvature.Dot(normal) < 0 {
+				curvature = curvature.Mul(-1)
+			}
+			normal = curvature
+		}
+		// keep the normal across the tangent as the tangent turns
+		normal = normal.Sub(tangent.Mul(normal.Dot(tangent)))
+		if normal.Len() < 1e-6 {
+			normal = tangent.Cross(binormals[maxInt(i-1, 0)])
+		}
+		normals[i] = normal.Normalize()
+		binormals[i] = tangent.Cross(normals[i])
+		normal = normals[i]
+	}
+	return
+}
+
+//GetParallelFrames returns rotation minimizing frames of path, with the double reflection
+//method of Wang et al.: the first normal is the one of GetFrenetFrames and every frame is the
+//previous one carried along the path with the least turn around the tangent, so rings built
+//on them don't twist.
+func GetParallelFrames(path []mgl32.Vec3) (tangents, normals, binormals []mgl32.Vec3) {
+	tangents = getPathTangents(path)
+	normals = make([]mgl32.Vec3, len(path))
+	binormals = make([]mgl32.Vec3, len(path))
+	if len(path) == 0 {
+		return
+	}
+	reflect := func(v, axis mgl32.Vec3, lengthSqr float32) mgl32.Vec3 {
+		return v.Sub(axis.Mul(2 * axis.Dot(v) / lengthSqr))
+	}
+	normal := getStartNormal(path, tangents)
+	for i, tangent := range tangents {
+		if i > 0 {
+			// reflect the frame by the plane between the points, then by the one that takes
+			// the reflected tangent onto the tangent
+			if step := path[i].Sub(path[i-1]); step.LenSqr() > 0 {
+				normal = reflect(normal, step, step.LenSqr())
+				reflected := reflect(tangents[i-1], step, step.LenSqr())
+				if across := tangent.Sub(reflected); across.LenSqr() > 0 {
+					normal = reflect(normal, across, across.LenSqr())
+				}
+			}
+		}
+		// rounding is taken back out so the frame stays orthonormal
+		normal = normal.Sub(tangent.Mul(normal.Dot(tangent)))
+		if normal.Len() < 1e-6 {
+			normal = tangent.Cross(binormals[maxInt(i-1, 0)])
+		}
+		normals[i] = normal.Normalize()
+		binormals[i] = tangent.Cross(normals[i])
+		normal = normals[i]
+	}
+	return
+}
+
+//getPathTangents returns the unit tangents of path by central differences.
+func getPathTangents(path []mgl32.Vec3) []mgl32.Vec3 {
+	tangents := make([]mgl32.Vec3, len(path))
+	for i := range path {
+		previous, next := path[maxInt(i-1, 0)], path[minInt(i+1, len(path)-1)]
+		if tangent := next.Sub(previous); tangent.Len() > 0 {
+			tangents[i] = tangent.Normalize()
+		} else if i > 0 {
+			tangents[i] = tangents[i-1]
+		} else {
+			tangents[i] = mgl32.Vec3{0, 1, 0}
+		}
+	}
+	return tangents
+}
+
+//getStartNormal is the first normal of the frames of path, from the first point that curves,
+//or from any axis across the tangent on a straight path or one that curves around it.
+func getStartNormal(path []mgl32.Vec3, tangents []mgl32.Vec3) mgl32.Vec3 {
+	for i := range path {
+		if normal := getCurvature(path, tangents, i); normal.Len() > 0 {
+			if normal.Sub(tangents[0].Mul(normal.Dot(tangents[0]))).Len() > 1e-3 {
+				return normal
+			}
+			break
+		}
+	}
+	if len(tangents) > 0 && math32.Abs(tangents[0].X()) > 0.9 {
+		return mgl32.Vec3{0, 0, 1}
+	}
+	return mgl32.Vec3{1, 0, 0}
+}
+
+//getCurvature is the direction the tangent turns towards at point i of path, or zero where
+//the path is straight.
+func getCurvature(path []mgl32.Vec3, tangents []mgl32.Vec3, i int) mgl32.Vec3 {
+	if i == 0 || i == len(path)-1 {
+		return mgl32.Vec3{}
+	}
+	tangent, in, out := tangents[i], path[i].Sub(path[i-1]), path[i+1].Sub(path[i])
+	if in.Len() == 0 || out.Len() == 0 {
+		return mgl32.Vec3{}
+	}
+	turn := out.Normalize().Sub(in.Normalize())
+	turn = turn.Sub(tangent.Mul(turn.Dot(tangent)))
+	if turn.Len() < 1e-4 {
+		return mgl32.Vec3{}
+	}
+	return turn.Normalize()
+}
+
+//getProfileNormals returns the normals of a 2D polyline, to the right of its direction and
+//averaged between segments. Repeated points split the normals into a hard edge and a polyline
+//ending where it starts is smoothed across its ends.
+func getProfileNormals(profile []mgl32.Vec2) []mgl32.Vec2 {
+	segment := func(i int) mgl32.Vec2 {
+		if i < 0 || i+1 >= len(profile) {
+			return mgl32.Vec2{}
+		}
+		d := profile[i+1].Sub(profile[i])
+		if d.Len() == 0 {
+			return d
+		}
+		return mgl32.Vec2{d.Y(), -d.X()}.Normalize()
+	}
+	closed := len(profile) > 2 && profile[0] == profile[len(profile)-1]
+	normals := make([]mgl32.Vec2, len(profile))
+	for i := range profile {
+		before, after := segment(i-1), segment(i)
+		switch {
+		case closed && i == 0:
+			before = segment(len(profile) - 2)
+		case closed && i == len(profile)-1:
+			after = segment(0)
+		}
+		// a repeated point takes the normal of its own side only
+		if i > 0 && profile[i-1] == profile[i] {
+			before = mgl32.Vec2{}
+		}
+		if i+1 < len(profile) && profile[i+1] == profile[i] {
+			after = mgl32.Vec2{}
+		}
+		if normal := before.Add(after); normal.Len() > 0 {
+			normals[i] = normal.Normalize()
+		}
+	}
+	return normals
+}
+
+//getPolylineV2 returns the length along a 2D polyline up to every point over its total length.
+func getPolylineV2(polyline []mgl32.Vec2) []float32 {
+	points := make([]mgl32.Vec3, len(polyline))
+	for i, point := range polyline {
+		points[i] = point.Vec3(0)
+	}
+	return getPolylineV3(points)
+}
+
+//getPolylineV3 is getPolylineV2 for a 3D polyline.
+func getPolylineV3(polyline []mgl32.Vec3) []float32 {
+	lengths := make([]float32, len(polyline))
+	if len(polyline) == 0 {
+		return lengths
+	}
+	for i := 1; i < len(polyline); i++ {
+		lengths[i] = lengths[i-1] + polyline[i].Sub(polyline[i-1]).Len()
+	}
+	if total := lengths[len(lengths)-1]; total > 0 {
+		for i := range lengths {
+			lengths[i] /= total
+		}
+	}
+	return lengths
+}
+
+//getLoftMesh joins rings of the same number of vertices with triangles, skipping the
+//degenerate ones left by points on an axis or repeated points.
+func getLoftMesh(rings [][]mgl32.Vec3, normals [][]mgl32.Vec3, uvs [][]mgl32.Vec2) *Mesh {
+	mesh := NewMesh(nil, nil, nil, nil)
+	for i := range rings {
+		mesh.appendVertices(rings[i], normals[i], uvs[i])
+	}
+	for i := 0; i+1 < len(rings); i++ {
+		for j := 0; j+1 < len(rings[i]); j++ {
+			first := uint32(i*len(rings[i]) + j)
+			next := first + uint32(len(rings[i]))
+			mesh.appendTriangle(first, next, first+1)
+			mesh.appendTriangle(first+1, next, next+1)
+		}
+	}
+	return mesh
+}
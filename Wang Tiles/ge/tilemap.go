@@ -0,0 +1,107 @@
+package ge
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//RenderTileMap composites the atlas tiles chosen in tiles, indexed [v][h],
+//into a single image on the CPU, drawing each tile tileSize pixels wide.
+//Tiles are cut from atlas with the same tileCords used for rendering, and
+//scaled with nearest neighbour sampling.
+func RenderTileMap(tiles [][]int, atlas image.Image, tileCords [][]mgl32.Vec2, tileSize int) *image.RGBA {
+	vTiles := len(tiles)
+	hTiles := 0
+	if vTiles > 0 {
+		hTiles = len(tiles[0])
+	}
+	out := image.NewRGBA(image.Rect(0, 0, hTiles*tileSize, vTiles*tileSize))
+	bounds := atlas.Bounds()
+	size := mgl32.Vec2{float32(bounds.Dx()), float32(bounds.Dy())}
+
+	for v := range tiles {
+		for h, tile := range tiles[v] {
+			min, max := tileCords[tile][0], tileCords[tile][0]
+			for _, c := range tileCords[tile][1:] {
+				min = mgl32.Vec2{math32.Min(min.X(), c.X()), math32.Min(min.Y(), c.Y())}
+				max = mgl32.Vec2{math32.Max(max.X(), c.X()), math32.Max(max.Y(), c.Y())}
+			}
+			min, max = Mul2(min, size), Mul2(max, size)
+			for y := 0; y < tileSize; y++ {
+				srcY := int(min.Y() + (float32(y)+0.5)/float32(tileSize)*(max.Y()-min.Y()))
+				for x := 0; x < tileSize; x++ {
+					srcX := int(min.X() + (float32(x)+0.5)/float32(tileSize)*(max.X()-min.X()))
+					out.Set(h*tileSize+x, v*tileSize+y, atlas.At(bounds.Min.X+srcX, bounds.Min.Y+srcY))
+				}
+			}
+		}
+	}
+	return out
+}
+
+//SaveTileMapPNG writes img, usually made with RenderTileMap, to file.
+func SaveTileMapPNG(file string, img image.Image) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, img); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+//SaveTileMapCSV writes the tile index grid to file, one row of the map
+//per line.
+func SaveTileMapCSV(file string, tiles [][]int) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(out)
+	for _, row := range tiles {
+		record := make([]string, len(row))
+		for h, tile := range row {
+			record[h] = strconv.Itoa(tile)
+		}
+		if err := writer.Write(record); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+//SaveTileMapJSON writes the tile index grid to file as an array of rows,
+//one row per line so maps diff cleanly.
+func SaveTileMapJSON(file string, tiles [][]int) error {
+	data := []byte("[\n")
+	for v, row := range tiles {
+		rowData, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\t')
+		data = append(data, rowData...)
+		if v < len(tiles)-1 {
+			data = append(data, ',')
+		}
+		data = append(data, '\n')
+	}
+	data = append(data, "]\n"...)
+	return ioutil.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,115 @@
+package ge
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//Tile is one entry of a TileSet: its pixel rectangle in the atlas and the
+//colour of each of its edges. Two tiles may be placed next to each other
+//when the touching edges have the same colour. An empty colour never
+//matches, which keeps a tile from having a neighbour on that side.
+type Tile struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	N string `json:"n"`
+	E string `json:"e"`
+	S string `json:"s"`
+	W string `json:"w"`
+
+	// Corner colours of corner Wang sets, see CornerTiles
+	NW string `json:"nw,omitempty"`
+	NE string `json:"ne,omitempty"`
+	SW string `json:"sw,omitempty"`
+	SE string `json:"se,omitempty"`
+
+	// Blob is the neighbour mask of a tile in a 47-tile blob set, see BlobTiles
+	Blob *int `json:"blob,omitempty"`
+}
+
+//TileSet describes a Wang tile atlas.
+type TileSet struct {
+	// Image is the atlas file, relative to the tile set file when loaded
+	// with LoadTileSet.
+	Image string `json:"image"`
+
+	// Grid, when set, gives the pixel rectangle of every tile in order and
+	// the rectangles in Tiles may be left out.
+	Grid *AtlasGrid `json:"grid,omitempty"`
+
+	// Inset is the number of pixels trimmed from every side of each tile
+	// when computing texture coordinates, see SliceAtlas.
+	Inset float32 `json:"inset"`
+
+	Tiles []Tile `json:"tiles"`
+}
+
+//LoadTileSet reads a JSON tile set description from file.
+func LoadTileSet(file string) (*TileSet, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var tileSet TileSet
+	if err := json.Unmarshal(data, &tileSet); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if len(tileSet.Tiles) == 0 {
+		return nil, fmt.Errorf("%s: tile set has no tiles", file)
+	}
+	if tileSet.Grid != nil {
+		rects := tileSet.Grid.Rects()
+		if len(rects) != len(tileSet.Tiles) {
+			return nil, fmt.Errorf("%s: grid has %d cells for %d tiles", file, len(rects), len(tileSet.Tiles))
+		}
+		for i, rect := range rects {
+			tile := &tileSet.Tiles[i]
+			tile.X, tile.Y = rect.Min.X, rect.Min.Y
+			tile.Width, tile.Height = rect.Dx(), rect.Dy()
+		}
+	}
+	if tileSet.Image != "" && !filepath.IsAbs(tileSet.Image) {
+		tileSet.Image = filepath.Join(filepath.Dir(file), tileSet.Image)
+	}
+	return &tileSet, nil
+}
+
+//AdjacencyList derives the adjacency list GetSquareWangTiles and
+//WangGenerator expect from the tile edge colours: entry [t][0] lists the
+//tiles whose west edge matches the east edge of t, entry [t][1] the tiles
+//whose north edge matches its south edge.
+func (ts *TileSet) AdjacencyList() [][][]int {
+	adjacencyList := make([][][]int, len(ts.Tiles))
+	for t, tile := range ts.Tiles {
+		right, bottom := []int{}, []int{}
+		for n, neighbour := range ts.Tiles {
+			if tile.E != "" && tile.E == neighbour.W {
+				right = append(right, n)
+			}
+			if tile.S != "" && tile.S == neighbour.N {
+				bottom = append(bottom, n)
+			}
+		}
+		adjacencyList[t] = [][]int{right, bottom}
+	}
+	return adjacencyList
+}
+
+//TileCoords returns the texture coordinates of every tile for an atlas of
+//the given size in pixels, in the order GetSquareTiles uses for its quads.
+func (ts *TileSet) TileCoords(width, height int) [][]mgl32.Vec2 {
+	tileCords := make([][]mgl32.Vec2, len(ts.Tiles))
+	for t, tile := range ts.Tiles {
+		rect := image.Rect(tile.X, tile.Y, tile.X+tile.Width, tile.Y+tile.Height)
+		tileCords[t] = getTileCoords(rect, ts.Inset, width, height)
+	}
+	return tileCords
+}
@@ -0,0 +1,184 @@
+package ge
+
+import (
+	"math/rand"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//TreeSpecies are the parameters GetTreeMesh grows a tree from. Branches grow recursively from
+//the trunk, like the rules of an L-system, with random variations picked by the seed.
+type TreeSpecies struct {
+	// TrunkLength and TrunkRadius size the trunk, standing on the origin
+	TrunkLength, TrunkRadius float32
+	// Levels of branches grown on top of the trunk, 0 leaves a bare trunk
+	Levels int
+	// Branches grown from every branch of the previous level, spread along its upper part
+	// from BranchStart, a fraction of its length, to its tip
+	Branches    int
+	BranchStart float32
+	// LengthRatio and RadiusRatio scale every branch from the point of its parent it grows at
+	LengthRatio, RadiusRatio float32
+	// BranchAngle is how far branches open from their parent, in radians, and AngleJitter how
+	// much that changes from branch to branch
+	BranchAngle, AngleJitter float32
+	// Gravity bends branches down, or up when negative, along their length
+	Gravity float32
+	// Wobble is how much branches turn at random between their segments
+	Wobble float32
+	// Segments along every branch and Sides around it
+	Segments, Sides int
+	// Leaves cards on every branch of the last level, of LeafSize
+	Leaves   int
+	LeafSize float32
+}
+
+//OakSpecies is a broad tree with wide open branches.
+var OakSpecies = TreeSpecies{
+	TrunkLength: 1.5, TrunkRadius: .12,
+	Levels: 3, Branches: 4, BranchStart: .4,
+	LengthRatio: .65, RadiusRatio: .55,
+	BranchAngle: mgl32.DegToRad(50), AngleJitter: mgl32.DegToRad(15),
+	Gravity: .15, Wobble: .15,
+	Segments: 5, Sides: 8,
+	Leaves: 8, LeafSize: .25,
+}
+
+//PineSpecies is a narrow tree with many short branches hanging from a long trunk.
+var PineSpecies = TreeSpecies{
+	TrunkLength: 3, TrunkRadius: .1,
+	Levels: 2, Branches: 10, BranchStart: .25,
+	LengthRatio: .35, RadiusRatio: .4,
+	BranchAngle: mgl32.DegToRad(80), AngleJitter: mgl32.DegToRad(10),
+	Gravity: .3, Wobble: .05,
+	Segments: 4, Sides: 6,
+	Leaves: 6, LeafSize: .2,
+}
+
+//GetTreeMesh grows a tree of species in a single mesh, to be drawn with one texture holding the
+//bark on its left half and the leaves on its right half. The bark is wrapped around the
+//branches on u from 0 to 0.5 and repeats along them on v, so the texture must repeat, the leaf
+//cards take the whole right half and show both faces; the shader should discard the transparent
+//texels of the leaves. The same seed always grows the same tree.
+func GetTreeMesh(species TreeSpecies, seed int64) *Mesh {
+	g := &treeGrower{species: species, random: rand.New(rand.NewSource(seed)), mesh: NewMesh(nil, nil, nil, nil)}
+	g.grow(mgl32.Vec3{}, mgl32.Vec3{0, 1, 0}, species.TrunkLength, species.TrunkRadius, 0)
+	return g.mesh
+}
+
+type treeGrower struct {
+	species TreeSpecies
+	random  *rand.Rand
+	mesh    *Mesh
+}
+
+//grow adds a branch from start along direction and the branches and leaves growing from it.
+func (g *treeGrower) grow(start, direction mgl32.Vec3, length, radius float32, level int) {
+	s := g.species
+	segments, sides := maxInt(s.Segments, 1), maxInt(s.Sides, 3)
+	last := level == s.Levels
+
+	// the branch bends down with gravity and turns at random a little on every segment
+	path := []mgl32.Vec3{start}
+	for i := 1; i <= segments; i++ {
+		direction = direction.Add(mgl32.Vec3{0, -s.Gravity / float32(segments), 0}).Add(g.randomVec3().Mul(s.Wobble / float32(segments)))
+		if direction.Len() > 0 {
+			direction = direction.Normalize()
+		}
+		path = append(path, path[i-1].Add(direction.Mul(length/float32(segments))))
+	}
+	endRadius := radius * s.RadiusRatio
+	if last {
+		endRadius = 0
+	}
+	radiusAt := func(t float32) float32 {
+		return radius + (endRadius-radius)*t
+	}
+
+	// parallel frames keep the rings from twisting on the bends, and the bark from spiraling
+	tangents, normals, binormals := GetParallelFrames(path)
+	circumference := 2 * math32.Pi * math32.Max(s.TrunkRadius, 1e-6)
+	var rings, ringNormals [][]mgl32.Vec3
+	var uvs [][]mgl32.Vec2
+	for i, center := range path {
+		t := float32(i) / float32(segments)
+		// the bark keeps its proportions on every branch, its texture repeats along them
+		v := t * length / circumference
+		var ring, ringNormal []mgl32.Vec3
+		var ringUVs []mgl32.Vec2
+		for j := 0; j <= sides; j++ {
+			angle := 2 * math32.Pi * float32(j) / float32(sides)
+			outward := normals[i].Mul(math32.Cos(angle)).Add(binormals[i].Mul(math32.Sin(angle)))
+			ring = append(ring, center.Add(outward.Mul(radiusAt(t))))
+			// tilted along the taper like GetCylinderNormals3
+			ringNormal = append(ringNormal, outward.Add(tangents[i].Mul((radius-endRadius)/length)).Normalize())
+			ringUVs = append(ringUVs, mgl32.Vec2{0.5 * float32(j) / float32(sides), v})
+		}
+		rings, ringNormals, uvs = append(rings, ring), append(ringNormals, ringNormal), append(uvs, ringUVs)
+	}
+	g.mesh.Append(getLoftMesh(rings, ringNormals, uvs))
+
+	pointAt := func(t float32) (mgl32.Vec3, mgl32.Vec3) {
+		position := t * float32(segments)
+		i := minInt(int(position), segments-1)
+		return path[i].Add(path[i+1].Sub(path[i]).Mul(position - float32(i))), tangents[i]
+	}
+	if last {
+		for leaf := 0; leaf < s.Leaves; leaf++ {
+			point, _ := pointAt(0.5 + 0.5*g.random.Float32())
+			g.addLeaf(point)
+		}
+		return
+	}
+
+	// children spread around their parent by the golden angle, like leaves on a stem
+	azimuth := g.random.Float32() * 2 * math32.Pi
+	for child := 0; child < s.Branches; child++ {
+		t := s.BranchStart + (1-s.BranchStart)*(float32(child)+g.random.Float32())/float32(s.Branches)
+		t = math32.Min(t, 1)
+		point, along := pointAt(t)
+		azimuth += 2.39996
+		opening := s.BranchAngle + s.AngleJitter*(2*g.random.Float32()-1)
+		across := normals[0].Mul(math32.Cos(azimuth)).Add(binormals[0].Mul(math32.Sin(azimuth)))
+		across = across.Sub(along.Mul(across.Dot(along)))
+		if across.Len() == 0 {
+			continue
+		}
+		childDirection := along.Mul(math32.Cos(opening)).Add(across.Normalize().Mul(math32.Sin(opening)))
+		// branches higher up are shorter, like the crown of most trees
+		childLength := length * s.LengthRatio * (1.2 - 0.4*t)
+		g.grow(point, childDirection, childLength, radiusAt(t)*s.RadiusRatio, level+1)
+	}
+}
+
+//addLeaf adds a leaf card hanging from point in a random direction, with a face on each side.
+func (g *treeGrower) addLeaf(point mgl32.Vec3) {
+	size := g.species.LeafSize
+	out := g.randomVec3()
+	if out.Len() == 0 {
+		out = mgl32.Vec3{1, 0, 0}
+	}
+	out = out.Add(mgl32.Vec3{0, -0.3, 0}).Normalize()
+	side := out.Cross(mgl32.Vec3{0, 1, 0})
+	if side.Len() < 1e-3 {
+		side = mgl32.Vec3{1, 0, 0}
+	}
+	side = side.Normalize().Mul(size / 2)
+	normal := side.Cross(out).Normalize()
+	tip := out.Mul(size)
+	corners := []mgl32.Vec3{point.Sub(side), point.Add(side), point.Sub(side).Add(tip), point.Add(side).Add(tip)}
+	uvs := []mgl32.Vec2{{0.5, 1}, {1, 1}, {0.5, 0}, {1, 0}}
+	g.mesh.AppendStrip(corners, GetConstantNormals3(normal, 4), uvs)
+	g.mesh.AppendStrip(corners, GetConstantNormals3(normal.Mul(-1), 4), uvs)
+}
+
+//randomVec3 is a random direction inside the unit sphere.
+func (g *treeGrower) randomVec3() mgl32.Vec3 {
+	for {
+		v := mgl32.Vec3{2*g.random.Float32() - 1, 2*g.random.Float32() - 1, 2*g.random.Float32() - 1}
+		if v.LenSqr() <= 1 {
+			return v
+		}
+	}
+}
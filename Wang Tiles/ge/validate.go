@@ -0,0 +1,113 @@
+package ge
+
+import (
+	"fmt"
+	"strings"
+)
+
+//TileViolation is a pair of neighbouring tiles not allowed by the
+//adjacency list.
+type TileViolation struct {
+	// Cell of the left or top tile of the pair
+	H, V int
+	// "right" or "bottom", the side of H, V where the neighbour is
+	Side            string
+	Tile, Neighbour int
+}
+
+//TileRun is a straight run of cells holding the same tile.
+type TileRun struct {
+	Tile       int
+	H, V       int // first cell of the run
+	Length     int
+	Horizontal bool
+}
+
+//TileMapReport summarizes a tile index grid, see ValidateTileMap.
+type TileMapReport struct {
+	Violations []TileViolation
+	// Usage[t] counts the cells that hold tile t
+	Usage []int
+	// LongestRun is the longest horizontal or vertical run of one tile
+	LongestRun TileRun
+}
+
+//ValidateTileMap checks every right and bottom neighbour of tiles, indexed
+//[v][h], against adjacencyList and gathers usage statistics.
+//adjacencyList[t][0] lists the tiles allowed to the right of t and
+//adjacencyList[t][1] the tiles allowed below it.
+func ValidateTileMap(tiles [][]int, adjacencyList [][][]int) TileMapReport {
+	report := TileMapReport{Usage: make([]int, len(adjacencyList))}
+	allowed := func(tile, side, neighbour int) bool {
+		if tile < 0 || tile >= len(adjacencyList) || len(adjacencyList[tile]) <= side {
+			return false
+		}
+		for _, t := range adjacencyList[tile][side] {
+			if t == neighbour {
+				return true
+			}
+		}
+		return false
+	}
+	run := func(h, v, length int, horizontal bool) {
+		if length > report.LongestRun.Length {
+			report.LongestRun = TileRun{Tile: tiles[v][h], H: h, V: v, Length: length, Horizontal: horizontal}
+		}
+	}
+
+	for v := range tiles {
+		for h, tile := range tiles[v] {
+			if tile >= 0 && tile < len(report.Usage) {
+				report.Usage[tile]++
+			}
+			if h+1 < len(tiles[v]) && !allowed(tile, 0, tiles[v][h+1]) {
+				report.Violations = append(report.Violations, TileViolation{H: h, V: v, Side: "right", Tile: tile, Neighbour: tiles[v][h+1]})
+			}
+			if v+1 < len(tiles) && h < len(tiles[v+1]) && !allowed(tile, 1, tiles[v+1][h]) {
+				report.Violations = append(report.Violations, TileViolation{H: h, V: v, Side: "bottom", Tile: tile, Neighbour: tiles[v+1][h]})
+			}
+
+			// runs are measured from their first cell only
+			if h == 0 || tiles[v][h-1] != tile {
+				length := 1
+				for h+length < len(tiles[v]) && tiles[v][h+length] == tile {
+					length++
+				}
+				run(h, v, length, true)
+			}
+			if v == 0 || h >= len(tiles[v-1]) || tiles[v-1][h] != tile {
+				length := 1
+				for v+length < len(tiles) && h < len(tiles[v+length]) && tiles[v+length][h] == tile {
+					length++
+				}
+				run(h, v, length, false)
+			}
+		}
+	}
+	return report
+}
+
+//Valid reports whether the map has no violated edges.
+func (r TileMapReport) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+func (r TileMapReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d violated edges\n", len(r.Violations))
+	for _, violation := range r.Violations {
+		fmt.Fprintf(&b, "  cell (%d, %d): tile %d does not allow tile %d on its %s\n",
+			violation.H, violation.V, violation.Tile, violation.Neighbour, violation.Side)
+	}
+	b.WriteString("tile usage:")
+	for tile, count := range r.Usage {
+		fmt.Fprintf(&b, " %d:%d", tile, count)
+	}
+	direction := "vertical"
+	if r.LongestRun.Horizontal {
+		direction = "horizontal"
+	}
+	fmt.Fprintf(&b, "\nlongest run: tile %d repeated %d times, %s from cell (%d, %d)\n",
+		r.LongestRun.Tile, r.LongestRun.Length, direction, r.LongestRun.H, r.LongestRun.V)
+	return b.String()
+}
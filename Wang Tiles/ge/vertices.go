@@ -1,47 +1,19 @@
 package ge
 
 import (
+	"github.com/StevenTarazona/glcore/gfx"
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-//CreateVAO ...
-func CreateVAO(vertices []mgl32.Vec3, tCoords []mgl32.Vec2, indices []uint32) uint32 {
-
-	var VAO uint32
-	gl.GenVertexArrays(1, &VAO)
-
-	// Bind the Vertex Array Object first, then bind and set vertex buffer(s) and attribute pointers()
-	gl.BindVertexArray(VAO)
-
-	// copy vertices data into VBO (it needs to be bound first)
-	var VBO uint32
-	gl.GenBuffers(1, &VBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4*3, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-
-	if len(tCoords) > 0 {
-		var TBO uint32
-		gl.GenBuffers(1, &TBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, TBO)
-		gl.BufferData(gl.ARRAY_BUFFER, len(tCoords)*4*3, gl.Ptr(tCoords), gl.STATIC_DRAW)
-		gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 2*4, gl.PtrOffset(0))
-		gl.EnableVertexAttribArray(1)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	}
-	if len(indices) > 0 {
-		var EBO uint32
-		gl.GenBuffers(1, &EBO)
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4*2, gl.Ptr(indices), gl.STATIC_DRAW)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	}
-	gl.BindVertexArray(0)
-
-	return VAO
+//MeshLayout is the vertex layout of Mesh: positions at attribute location 0,
+//normals at 1 and texture coordinates at 2, the layout the phong shaders expect.
+var MeshLayout = gfx.VertexLayout{
+	Attributes: []gfx.VertexAttribute{
+		{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT},
+		{Name: "normal", Location: 1, Size: 3, Type: gl.FLOAT},
+		{Name: "texCoord", Location: 2, Size: 2, Type: gl.FLOAT},
+	},
 }
 
 //Mul defines multiplication of 2 vert3
@@ -61,7 +33,8 @@ func Translate(vertices []mgl32.Vec3, vertex mgl32.Vec3) (translated []mgl32.Vec
 	return
 }
 
-//Transform defines multiplication of vert3 array and a ver3
+//Transform defines multiplication of vert3 array and a ver3, a scale along each axis. Use
+//TransformMat4 for any other transformation.
 func Transform(vertices []mgl32.Vec3, vertex mgl32.Vec3) (translated []mgl32.Vec3) {
 	for _, ver := range vertices {
 		translated = append(translated, Mul(ver, vertex))
@@ -69,6 +42,14 @@ func Transform(vertices []mgl32.Vec3, vertex mgl32.Vec3) (translated []mgl32.Vec
 	return
 }
 
+//TransformMat4 applies matrix to every vertex as a point, dividing by w for projections.
+func TransformMat4(vertices []mgl32.Vec3, matrix mgl32.Mat4) (transformed []mgl32.Vec3) {
+	for _, ver := range vertices {
+		transformed = append(transformed, mgl32.TransformCoordinate(ver, matrix))
+	}
+	return
+}
+
 func Transform2(vertices []mgl32.Vec2, vertex mgl32.Vec2) (translated []mgl32.Vec2) {
 	for _, ver := range vertices {
 		translated = append(translated, Mul2(ver, vertex))
@@ -0,0 +1,410 @@
+package ge
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//maxWangBacktracks bounds the search so an unsatisfiable tile set fails
+//with an error instead of hanging the program.
+const maxWangBacktracks = 1 << 16
+
+//Directions from a cell to its neighbours, used to index wangSolver.allowed.
+const (
+	wangRight = iota
+	wangBottom
+	wangLeft
+	wangTop
+)
+
+//tileMask is a bit set of tile indices.
+type tileMask []uint64
+
+func newTileMask(numTiles int) tileMask {
+	return make(tileMask, (numTiles+63)/64)
+}
+
+func (m tileMask) set(tile int)      { m[tile/64] |= 1 << uint(tile%64) }
+func (m tileMask) has(tile int) bool { return m[tile/64]&(1<<uint(tile%64)) != 0 }
+
+func (m tileMask) empty() bool {
+	for _, word := range m {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m tileMask) tiles() (tiles []int) {
+	for w, word := range m {
+		for b := 0; word != 0; b++ {
+			if word&1 != 0 {
+				tiles = append(tiles, w*64+b)
+			}
+			word >>= 1
+		}
+	}
+	return
+}
+
+//wangSolver fills a vTiles x hTiles grid with tile indices so that every
+//right and bottom neighbour is allowed by the adjacency list. It keeps the
+//set of tiles still possible in every cell and propagates each choice to
+//the whole grid, so fixed borders are felt long before they are reached.
+type wangSolver struct {
+	hTiles, vTiles int
+	numTiles       int
+
+	// allowed[dir][a] holds the tiles that may be placed next to a in
+	// direction dir.
+	allowed [4][]tileMask
+
+	rand    *rand.Rand
+	domains []tileMask
+
+	// trail records domains before they were narrowed so they can be
+	// restored when backtracking.
+	trail []trailEntry
+
+	backtracks int
+
+	// deepest cell that ran out of candidates, used for error reporting
+	failedCell int
+}
+
+type trailEntry struct {
+	cell   int
+	domain tileMask
+}
+
+func newWangSolver(hTiles, vTiles int, adjacencyList [][][]int, borders WangBorders, r *rand.Rand) (*wangSolver, error) {
+	numTiles := len(adjacencyList)
+	if hTiles <= 0 || vTiles <= 0 {
+		return nil, fmt.Errorf("wang tiles: invalid grid size %dx%d", hTiles, vTiles)
+	}
+	if numTiles == 0 {
+		return nil, fmt.Errorf("wang tiles: empty tile set")
+	}
+
+	s := &wangSolver{
+		hTiles:     hTiles,
+		vTiles:     vTiles,
+		numTiles:   numTiles,
+		rand:       r,
+		domains:    make([]tileMask, hTiles*vTiles),
+		failedCell: -1,
+	}
+	for dir := range s.allowed {
+		s.allowed[dir] = make([]tileMask, numTiles)
+		for tile := range s.allowed[dir] {
+			s.allowed[dir][tile] = newTileMask(numTiles)
+		}
+	}
+	for tile, sides := range adjacencyList {
+		if len(sides) < 2 {
+			return nil, fmt.Errorf("wang tiles: tile %d needs right and bottom adjacency lists", tile)
+		}
+		for dir := wangRight; dir <= wangBottom; dir++ {
+			for _, neighbour := range sides[dir] {
+				if neighbour < 0 || neighbour >= numTiles {
+					return nil, fmt.Errorf("wang tiles: tile %d references unknown tile %d", tile, neighbour)
+				}
+				s.allowed[dir][tile].set(neighbour)
+				s.allowed[dir+2][neighbour].set(tile)
+			}
+		}
+	}
+
+	for cell := range s.domains {
+		s.domains[cell] = newTileMask(numTiles)
+		for tile := 0; tile < numTiles; tile++ {
+			s.domains[cell].set(tile)
+		}
+	}
+	for _, border := range []struct {
+		name  string
+		tiles []int
+		size  int
+		// cell next to the i-th border tile and direction from the border to it
+		cell func(i int) int
+		dir  int
+	}{
+		{"left", borders.Left, vTiles, func(i int) int { return i * hTiles }, wangRight},
+		{"top", borders.Top, hTiles, func(i int) int { return i }, wangBottom},
+		{"right", borders.Right, vTiles, func(i int) int { return hTiles - 1 + i*hTiles }, wangLeft},
+		{"bottom", borders.Bottom, hTiles, func(i int) int { return i + (vTiles-1)*hTiles }, wangTop},
+	} {
+		if border.tiles == nil {
+			continue
+		}
+		if len(border.tiles) != border.size {
+			return nil, fmt.Errorf("wang tiles: %s border has %d tiles, want %d", border.name, len(border.tiles), border.size)
+		}
+		for i, tile := range border.tiles {
+			if tile < 0 || tile >= numTiles {
+				return nil, fmt.Errorf("wang tiles: %s border references unknown tile %d", border.name, tile)
+			}
+			domain := s.domains[border.cell(i)]
+			for w := range domain {
+				domain[w] &= s.allowed[border.dir][tile][w]
+			}
+		}
+	}
+	return s, nil
+}
+
+//neighbour returns the cell next to cell in direction dir, or -1.
+func (s *wangSolver) neighbour(cell, dir int) int {
+	h, v := cell%s.hTiles, cell/s.hTiles
+	switch dir {
+	case wangRight:
+		h++
+	case wangBottom:
+		v++
+	case wangLeft:
+		h--
+	case wangTop:
+		v--
+	}
+	if h < 0 || h >= s.hTiles || v < 0 || v >= s.vTiles {
+		return -1
+	}
+	return h + v*s.hTiles
+}
+
+//narrow intersects the domain of cell with mask, recording the old domain
+//on the trail. It reports whether the domain changed.
+func (s *wangSolver) narrow(cell int, mask tileMask) bool {
+	domain := s.domains[cell]
+	changed := false
+	for w := range domain {
+		if domain[w]&mask[w] != domain[w] {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return false
+	}
+	s.trail = append(s.trail, trailEntry{cell: cell, domain: append(tileMask(nil), domain...)})
+	for w := range domain {
+		domain[w] &= mask[w]
+	}
+	return true
+}
+
+//propagate removes from the neighbours of the queued cells every tile that
+//no longer has a compatible tile next to it. It returns false when some
+//cell is left without candidates.
+func (s *wangSolver) propagate(queue []int) bool {
+	support := newTileMask(s.numTiles)
+	for len(queue) > 0 {
+		cell := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for dir := range s.allowed {
+			next := s.neighbour(cell, dir)
+			if next < 0 {
+				continue
+			}
+			for w := range support {
+				support[w] = 0
+			}
+			for _, tile := range s.domains[cell].tiles() {
+				for w := range support {
+					support[w] |= s.allowed[dir][tile][w]
+				}
+			}
+			if s.narrow(next, support) {
+				if s.domains[next].empty() {
+					if next > s.failedCell {
+						s.failedCell = next
+					}
+					return false
+				}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return true
+}
+
+//undo restores every domain narrowed since the trail had length mark.
+func (s *wangSolver) undo(mark int) {
+	for i := len(s.trail) - 1; i >= mark; i-- {
+		s.domains[s.trail[i].cell] = s.trail[i].domain
+	}
+	s.trail = s.trail[:mark]
+}
+
+//solve makes the domains consistent and then fixes cells in row-major
+//order, backtracking whenever a choice leaves some cell without options.
+func (s *wangSolver) solve() bool {
+	queue := make([]int, len(s.domains))
+	for cell := range queue {
+		if s.domains[cell].empty() {
+			s.failedCell = cell
+			return false
+		}
+		queue[cell] = cell
+	}
+	return s.propagate(queue) && s.assign(0)
+}
+
+func (s *wangSolver) assign(cell int) bool {
+	if cell == len(s.domains) {
+		return true
+	}
+
+	options := s.domains[cell].tiles()
+	s.rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	for _, tile := range options {
+		mark := len(s.trail)
+		only := newTileMask(s.numTiles)
+		only.set(tile)
+		s.narrow(cell, only)
+		if s.propagate([]int{cell}) && s.assign(cell+1) {
+			return true
+		}
+		s.undo(mark)
+		if s.backtracks > maxWangBacktracks {
+			break
+		}
+		s.backtracks++
+	}
+
+	if cell > s.failedCell {
+		s.failedCell = cell
+	}
+	return false
+}
+
+//tile returns the tile chosen for cell once solve has succeeded.
+func (s *wangSolver) tile(cell int) int {
+	return s.domains[cell].tiles()[0]
+}
+
+//WangGenerator chooses Wang tiles for a grid using its own random source,
+//so the same seed always produces the same map.
+type WangGenerator struct {
+	adjacencyList [][][]int
+	rand          *rand.Rand
+}
+
+//NewWangGenerator returns a generator for the tile set described by
+//adjacencyList, seeded with seed. adjacencyList[t][0] lists the tiles
+//allowed to the right of t and adjacencyList[t][1] the tiles allowed below it.
+func NewWangGenerator(adjacencyList [][][]int, seed int64) *WangGenerator {
+	return NewWangGeneratorFromRand(adjacencyList, rand.New(rand.NewSource(seed)))
+}
+
+//NewWangGeneratorFromRand is like NewWangGenerator but draws from r.
+func NewWangGeneratorFromRand(adjacencyList [][][]int, r *rand.Rand) *WangGenerator {
+	return &WangGenerator{
+		adjacencyList: adjacencyList,
+		rand:          r,
+	}
+}
+
+//WangBorders holds tiles already placed around a grid so that a new grid
+//joins its neighbours without seams. Left and Right hold one tile per row,
+//Top and Bottom one per column; nil sides are left unconstrained.
+type WangBorders struct {
+	Left, Top, Right, Bottom []int
+}
+
+//Generate returns a vTiles x hTiles grid of tile indices, indexed [v][h],
+//where every right and bottom neighbour is allowed by the adjacency list.
+func (g *WangGenerator) Generate(hTiles, vTiles int) ([][]int, error) {
+	return g.GenerateBordered(hTiles, vTiles, WangBorders{})
+}
+
+//GenerateBordered is like Generate but the grid must also fit the tiles in
+//borders.
+func (g *WangGenerator) GenerateBordered(hTiles, vTiles int, borders WangBorders) ([][]int, error) {
+	s, err := newWangSolver(hTiles, vTiles, g.adjacencyList, borders, g.rand)
+	if err != nil {
+		return nil, err
+	}
+	if !s.solve() {
+		h, v := s.failedCell%hTiles, s.failedCell/hTiles
+		if s.backtracks > maxWangBacktracks {
+			return nil, fmt.Errorf("wang tiles: gave up after %d backtracks, cell (%d, %d) could not be satisfied", s.backtracks, h, v)
+		}
+		return nil, fmt.Errorf("wang tiles: no tile satisfies cell (%d, %d)", h, v)
+	}
+
+	tiles := make([][]int, vTiles)
+	for v := range tiles {
+		tiles[v] = make([]int, hTiles)
+		for h := range tiles[v] {
+			tiles[v][h] = s.tile(h + v*hTiles)
+		}
+	}
+	return tiles, nil
+}
+
+//getTileableAdjacency returns a copy of adjacencyList restricted to the
+//tiles that can be surrounded on every side, dropping the rest until none
+//is left without a neighbour in some direction. Tiles that may only sit on
+//the edge of a map keep their index but lose all their neighbours, so the
+//solver never places them in a grid that has to grow in every direction.
+func getTileableAdjacency(adjacencyList [][][]int) ([][][]int, error) {
+	numTiles := len(adjacencyList)
+	kept := make([]bool, numTiles)
+	for tile, sides := range adjacencyList {
+		if len(sides) < 2 {
+			return nil, fmt.Errorf("wang tiles: tile %d needs right and bottom adjacency lists", tile)
+		}
+		kept[tile] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		// neighbours[dir][t] counts kept neighbours of t in each direction
+		var neighbours [4][]int
+		for dir := range neighbours {
+			neighbours[dir] = make([]int, numTiles)
+		}
+		for tile, sides := range adjacencyList {
+			if !kept[tile] {
+				continue
+			}
+			for dir := wangRight; dir <= wangBottom; dir++ {
+				for _, neighbour := range sides[dir] {
+					if neighbour >= 0 && neighbour < numTiles && kept[neighbour] {
+						neighbours[dir][tile]++
+						neighbours[dir+2][neighbour]++
+					}
+				}
+			}
+		}
+		for tile := range kept {
+			if !kept[tile] {
+				continue
+			}
+			for dir := range neighbours {
+				if neighbours[dir][tile] == 0 {
+					kept[tile] = false
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	tileable := make([][][]int, numTiles)
+	for tile, sides := range adjacencyList {
+		tileable[tile] = [][]int{{}, {}}
+		if !kept[tile] {
+			continue
+		}
+		for dir := wangRight; dir <= wangBottom; dir++ {
+			for _, neighbour := range sides[dir] {
+				if neighbour >= 0 && neighbour < numTiles && kept[neighbour] {
+					tileable[tile][dir] = append(tileable[tile][dir], neighbour)
+				}
+			}
+		}
+	}
+	return tileable, nil
+}
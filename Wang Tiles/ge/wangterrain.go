@@ -0,0 +1,167 @@
+package ge
+
+import (
+	"sort"
+
+	"git.maze.io/go/math32"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+//WangTerrain is an endless Wang tile ground split in square chunks. Chunks
+//are generated around a position as it moves, matching the borders of the
+//chunks already loaded, and released once they fall out of range.
+type WangTerrain struct {
+	tileCords     [][]mgl32.Vec2
+	adjacencyList [][][]int
+	seed          int64
+
+	chunkTiles  int
+	tileLength  float32
+	radius      int
+	chunks      map[[2]int]*wangChunk
+	lastChunk   [2]int
+	initialized bool
+}
+
+type wangChunk struct {
+	tiles [][]int
+	mesh  *Mesh
+	world mgl32.Mat4
+}
+
+//NewWangTerrain returns a terrain of chunks with chunkTiles x chunkTiles
+//tiles of side tileLength, keeping radius chunks loaded in every direction
+//around the position given to Update. Tiles that cannot be surrounded on
+//every side by the adjacency list are never used, and a tile without its
+//right and bottom lists is an error.
+func NewWangTerrain(tileCords [][]mgl32.Vec2, adjacencyList [][][]int, seed int64, chunkTiles int, tileLength float32, radius int) (*WangTerrain, error) {
+	tileable, err := getTileableAdjacency(adjacencyList)
+	if err != nil {
+		return nil, err
+	}
+	return &WangTerrain{
+		tileCords:     tileCords,
+		adjacencyList: tileable,
+		seed:          seed,
+		chunkTiles:    chunkTiles,
+		tileLength:    tileLength,
+		radius:        radius,
+		chunks:        map[[2]int]*wangChunk{},
+	}, nil
+}
+
+//Update loads the chunks within range of position, nearest first, and
+//releases the ones out of range. Nothing is done while position stays in
+//the same chunk.
+func (t *WangTerrain) Update(position mgl32.Vec3) error {
+	chunkLength := float32(t.chunkTiles) * t.tileLength
+	center := [2]int{int(math32.Floor(position.X() / chunkLength)), int(math32.Floor(position.Z() / chunkLength))}
+	if t.initialized && center == t.lastChunk {
+		return nil
+	}
+	t.initialized = true
+	t.lastChunk = center
+
+	for key, chunk := range t.chunks {
+		if abs(key[0]-center[0]) > t.radius || abs(key[1]-center[1]) > t.radius {
+			chunk.mesh.Delete()
+			delete(t.chunks, key)
+		}
+	}
+
+	missing := [][2]int{}
+	for z := center[1] - t.radius; z <= center[1]+t.radius; z++ {
+		for x := center[0] - t.radius; x <= center[0]+t.radius; x++ {
+			if _, ok := t.chunks[[2]int{x, z}]; !ok {
+				missing = append(missing, [2]int{x, z})
+			}
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return chunkDistance(missing[i], center) < chunkDistance(missing[j], center)
+	})
+	for _, key := range missing {
+		if err := t.loadChunk(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//loadChunk generates the tiles of a chunk against its loaded neighbours and
+//uploads its mesh.
+func (t *WangTerrain) loadChunk(key [2]int) error {
+	borders := WangBorders{}
+	if left, ok := t.chunks[[2]int{key[0] - 1, key[1]}]; ok {
+		borders.Left = getTilesColumn(left.tiles, t.chunkTiles-1)
+	}
+	if right, ok := t.chunks[[2]int{key[0] + 1, key[1]}]; ok {
+		borders.Right = getTilesColumn(right.tiles, 0)
+	}
+	if top, ok := t.chunks[[2]int{key[0], key[1] - 1}]; ok {
+		borders.Top = top.tiles[t.chunkTiles-1]
+	}
+	if bottom, ok := t.chunks[[2]int{key[0], key[1] + 1}]; ok {
+		borders.Bottom = bottom.tiles[0]
+	}
+
+	// every chunk gets its own seed so revisited places look the same
+	// whenever their neighbours allow it
+	seed := t.seed ^ (int64(key[0])*73856093 + int64(key[1])*19349663)
+	tiles, err := NewWangGenerator(t.adjacencyList, seed).GenerateBordered(t.chunkTiles, t.chunkTiles, borders)
+	if err != nil {
+		return err
+	}
+
+	mesh := GetSquareTilesMesh(tiles, t.tileLength, t.tileCords)
+	if err := mesh.Upload(); err != nil {
+		return err
+	}
+	chunkLength := float32(t.chunkTiles) * t.tileLength
+	t.chunks[key] = &wangChunk{
+		tiles: tiles,
+		mesh:  mesh,
+		world: mgl32.Translate3D((float32(key[0])+0.5)*chunkLength, 0, (float32(key[1])+0.5)*chunkLength),
+	}
+	return nil
+}
+
+//Draw draws every loaded chunk with its own world transform, applied on top
+//of model. The caller binds the program and the atlas texture.
+func (t *WangTerrain) Draw(worldUniformLocation int32, model mgl32.Mat4) {
+	for _, chunk := range t.chunks {
+		world := model.Mul4(chunk.world)
+		gl.UniformMatrix4fv(worldUniformLocation, 1, false, &world[0])
+		chunk.mesh.Draw()
+	}
+}
+
+//Delete releases every loaded chunk.
+func (t *WangTerrain) Delete() {
+	for key, chunk := range t.chunks {
+		chunk.mesh.Delete()
+		delete(t.chunks, key)
+	}
+	t.initialized = false
+}
+
+func getTilesColumn(tiles [][]int, h int) []int {
+	column := make([]int, len(tiles))
+	for v := range tiles {
+		column[v] = tiles[v][h]
+	}
+	return column
+}
+
+func chunkDistance(a, b [2]int) int {
+	dx, dz := a[0]-b[0], a[1]-b[1]
+	return dx*dx + dz*dz
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
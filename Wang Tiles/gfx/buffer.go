@@ -0,0 +1,324 @@
+package gfx
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+//VertexAttribute describes one shader input of a VertexLayout.
+type VertexAttribute struct {
+	// Name is the key of the attribute data in VertexData
+	Name     string
+	Location uint32
+	// Size is the number of components per vertex, 1 to 4
+	Size int32
+	// Type is the component type, gl.FLOAT, gl.UNSIGNED_BYTE... The data of
+	// integer types reaches the shader as integers, for int, uint, ivec...
+	// inputs, unless Normalized maps it to floats from 0 or -1 to 1
+	Type       uint32
+	Normalized bool
+	// Divisor advances the attribute once every Divisor instances instead of
+	// once per vertex when it is not 0
+	Divisor uint32
+}
+
+//VertexLayout describes the attributes of a VertexArray and how they are
+//stored. Interleaved layouts keep every per vertex attribute in a single
+//buffer; attributes with a Divisor always get a buffer of their own.
+type VertexLayout struct {
+	Attributes  []VertexAttribute
+	Interleaved bool
+	// Usage is the buffer usage hint, gl.STATIC_DRAW when 0
+	Usage uint32
+}
+
+//VertexData holds the data of each attribute by name. Values are slices of
+//plain numbers or arrays of numbers, like []float32 or []mgl32.Vec3, with
+//Size components of Type per vertex. Missing or empty attributes are left
+//disabled.
+type VertexData map[string]interface{}
+
+//VertexArray is a vertex array object with the buffers it owns.
+type VertexArray struct {
+	handle  uint32
+	buffers map[string]uint32
+	layout  VertexLayout
+	ebo     uint32
+	// Count is the number of indices, or vertices when there are none
+	Count   int32
+	indexed bool
+}
+
+//NewVertexArray uploads data following layout, and indices when not empty.
+func NewVertexArray(layout VertexLayout, data VertexData, indices []uint32) (*VertexArray, error) {
+	if layout.Usage == 0 {
+		layout.Usage = gl.STATIC_DRAW
+	}
+	va := &VertexArray{buffers: map[string]uint32{}, layout: layout}
+
+	// validate everything before creating any GL object
+	attributes := []VertexAttribute{}
+	bytes := map[string][]byte{}
+	vertices := -1
+	stride := 0
+	for _, attribute := range layout.Attributes {
+		attributeBytes, err := attribute.getBytes(data[attribute.Name])
+		if err != nil {
+			return nil, fmt.Errorf("vertex attribute %s: %v", attribute.Name, err)
+		}
+		if len(attributeBytes) == 0 {
+			continue
+		}
+		vertexSize := attribute.vertexSize()
+		if vertexSize == 0 {
+			return nil, fmt.Errorf("vertex attribute %s: unsupported type 0x%x", attribute.Name, attribute.Type)
+		}
+		if len(attributeBytes)%vertexSize != 0 {
+			return nil, fmt.Errorf("vertex attribute %s: %d bytes is not a whole number of %d byte vertices", attribute.Name, len(attributeBytes), vertexSize)
+		}
+		if attribute.Divisor == 0 {
+			count := len(attributeBytes) / vertexSize
+			if vertices >= 0 && count != vertices {
+				return nil, fmt.Errorf("vertex attribute %s: has %d vertices, expected %d", attribute.Name, count, vertices)
+			}
+			vertices = count
+			stride += vertexSize
+		}
+		attributes = append(attributes, attribute)
+		bytes[attribute.Name] = attributeBytes
+	}
+	if vertices < 0 {
+		// arrays of only per instance attributes, like particles, draw one
+		// vertex per instance
+		vertices = 0
+		if len(attributes) > 0 {
+			vertices = 1
+		}
+	}
+
+	gl.GenVertexArrays(1, &va.handle)
+	gl.BindVertexArray(va.handle)
+
+	var interleaved []byte
+	offset := 0
+	if layout.Interleaved && stride > 0 {
+		interleaved = make([]byte, vertices*stride)
+		var VBO uint32
+		gl.GenBuffers(1, &VBO)
+		gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
+		va.buffers[""] = VBO
+	}
+	for _, attribute := range attributes {
+		vertexSize := attribute.vertexSize()
+		if layout.Interleaved && attribute.Divisor == 0 {
+			for v := 0; v < vertices; v++ {
+				copy(interleaved[v*stride+offset:], bytes[attribute.Name][v*vertexSize:(v+1)*vertexSize])
+			}
+			gl.BindBuffer(gl.ARRAY_BUFFER, va.buffers[""])
+			attribute.pointer(int32(stride), offset)
+			offset += vertexSize
+		} else {
+			var VBO uint32
+			gl.GenBuffers(1, &VBO)
+			gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
+			gl.BufferData(gl.ARRAY_BUFFER, len(bytes[attribute.Name]), gl.Ptr(bytes[attribute.Name]), layout.Usage)
+			attribute.pointer(int32(vertexSize), 0)
+			va.buffers[attribute.Name] = VBO
+		}
+		gl.EnableVertexAttribArray(attribute.Location)
+		gl.VertexAttribDivisor(attribute.Location, attribute.Divisor)
+	}
+	if len(interleaved) > 0 {
+		gl.BindBuffer(gl.ARRAY_BUFFER, va.buffers[""])
+		gl.BufferData(gl.ARRAY_BUFFER, len(interleaved), gl.Ptr(interleaved), layout.Usage)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	va.Count = int32(vertices)
+	if len(indices) > 0 {
+		gl.GenBuffers(1, &va.ebo)
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, va.ebo)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), layout.Usage)
+		va.Count = int32(len(indices))
+		va.indexed = true
+	}
+	gl.BindVertexArray(0)
+
+	return va, nil
+}
+
+//Update replaces the data of an attribute stored in a buffer of its own,
+//like the per instance attributes of interleaved layouts.
+func (va *VertexArray) Update(name string, data interface{}) error {
+	VBO, ok := va.buffers[name]
+	if !ok {
+		return fmt.Errorf("vertex attribute %s: has no buffer of its own", name)
+	}
+	var attribute VertexAttribute
+	for _, layoutAttribute := range va.layout.Attributes {
+		if layoutAttribute.Name == name {
+			attribute = layoutAttribute
+		}
+	}
+	bytes, err := attribute.getBytes(data)
+	if err != nil {
+		return fmt.Errorf("vertex attribute %s: %v", name, err)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
+	gl.BufferData(gl.ARRAY_BUFFER, len(bytes), gl.Ptr(bytes), va.layout.Usage)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return nil
+}
+
+func (va *VertexArray) Bind() {
+	gl.BindVertexArray(va.handle)
+}
+
+func (va *VertexArray) UnBind() {
+	gl.BindVertexArray(0)
+}
+
+//Draw draws all the indices, or vertices, of the array with mode, like
+//gl.TRIANGLES.
+func (va *VertexArray) Draw(mode uint32) {
+	va.DrawInstanced(mode, 1)
+}
+
+//DrawInstanced draws instances copies of the array with mode.
+func (va *VertexArray) DrawInstanced(mode uint32, instances int32) {
+	va.Bind()
+	if va.indexed {
+		gl.DrawElementsInstanced(mode, va.Count, gl.UNSIGNED_INT, nil, instances)
+	} else {
+		gl.DrawArraysInstanced(mode, 0, va.Count, instances)
+	}
+	va.UnBind()
+}
+
+//DrawPatches draws the array as patches of verticesPerPatch vertices for a
+//tessellated program.
+func (va *VertexArray) DrawPatches(verticesPerPatch int32) {
+	gl.PatchParameteri(gl.PATCH_VERTICES, verticesPerPatch)
+	va.Draw(gl.PATCHES)
+}
+
+func (va *VertexArray) Delete() {
+	for _, VBO := range va.buffers {
+		gl.DeleteBuffers(1, &VBO)
+	}
+	if va.indexed {
+		gl.DeleteBuffers(1, &va.ebo)
+	}
+	gl.DeleteVertexArrays(1, &va.handle)
+	va.buffers = map[string]uint32{}
+	va.indexed = false
+}
+
+func (va *VertexArray) Handle() uint32 {
+	return va.handle
+}
+
+//vertexSize is the size in bytes of the attribute for one vertex, or 0
+//for unknown types.
+func (attribute VertexAttribute) vertexSize() int {
+	switch attribute.Type {
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return int(attribute.Size)
+	case gl.SHORT, gl.UNSIGNED_SHORT, gl.HALF_FLOAT:
+		return 2 * int(attribute.Size)
+	case gl.INT, gl.UNSIGNED_INT, gl.FLOAT, gl.FIXED:
+		return 4 * int(attribute.Size)
+	case gl.DOUBLE:
+		return 8 * int(attribute.Size)
+	}
+	return 0
+}
+
+//pointer points the attribute at its data in the bound buffer, every
+//stride bytes from offset.
+func (attribute VertexAttribute) pointer(stride int32, offset int) {
+	if attribute.isInteger() && !attribute.Normalized {
+		gl.VertexAttribIPointer(attribute.Location, attribute.Size, attribute.Type, stride, gl.PtrOffset(offset))
+		return
+	}
+	gl.VertexAttribPointer(attribute.Location, attribute.Size, attribute.Type, attribute.Normalized, stride, gl.PtrOffset(offset))
+}
+
+func (attribute VertexAttribute) isInteger() bool {
+	switch attribute.Type {
+	case gl.BYTE, gl.UNSIGNED_BYTE, gl.SHORT, gl.UNSIGNED_SHORT, gl.INT, gl.UNSIGNED_INT:
+		return true
+	}
+	return false
+}
+
+//componentKinds are the Go kinds holding every component type.
+var componentKinds = map[uint32]reflect.Kind{
+	gl.BYTE:           reflect.Int8,
+	gl.UNSIGNED_BYTE:  reflect.Uint8,
+	gl.SHORT:          reflect.Int16,
+	gl.UNSIGNED_SHORT: reflect.Uint16,
+	gl.HALF_FLOAT:     reflect.Uint16,
+	gl.INT:            reflect.Int32,
+	gl.UNSIGNED_INT:   reflect.Uint32,
+	gl.FIXED:          reflect.Int32,
+	gl.FLOAT:          reflect.Float32,
+	gl.DOUBLE:         reflect.Float64,
+}
+
+//getBytes returns the memory of data, a slice of plain numbers or arrays
+//of them, without copying it. The numbers have to be of the Type of the
+//attribute and the arrays can't span more than one vertex.
+func (attribute VertexAttribute) getBytes(data interface{}) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unsupported data %T", data)
+	}
+	if value.Len() == 0 {
+		return nil, nil
+	}
+	if attribute.Size < 1 || attribute.Size > 4 {
+		return nil, fmt.Errorf("size %d, expected 1 to 4", attribute.Size)
+	}
+	kind, components := getComponents(value.Type().Elem())
+	if components == 0 {
+		return nil, fmt.Errorf("unsupported data %T", data)
+	}
+	if expected, ok := componentKinds[attribute.Type]; ok && kind != expected {
+		return nil, fmt.Errorf("data %T holds %v, expected %v for type 0x%x", data, kind, expected, attribute.Type)
+	}
+	if attribute.Size%int32(components) != 0 {
+		return nil, fmt.Errorf("data %T has %d components per element, expected a divisor of %d", data, components, attribute.Size)
+	}
+	return getBytes(value), nil
+}
+
+//getComponents returns the kind of the numbers of t, an array of arrays of
+//numbers or a number, and how many of them it holds, 0 when it isn't plain
+//data.
+func getComponents(t reflect.Type) (reflect.Kind, int) {
+	switch t.Kind() {
+	case reflect.Int8, reflect.Uint8, reflect.Int16, reflect.Uint16, reflect.Int32, reflect.Uint32,
+		reflect.Float32, reflect.Float64:
+		return t.Kind(), 1
+	case reflect.Array:
+		kind, components := getComponents(t.Elem())
+		return kind, t.Len() * components
+	}
+	return reflect.Invalid, 0
+}
+
+//getBytes returns the memory of a slice without copying it.
+func getBytes(slice reflect.Value) []byte {
+	size := slice.Len() * int(slice.Type().Elem().Size())
+	var bytes []byte
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&bytes))
+	header.Data, header.Len, header.Cap = slice.Pointer(), size, size
+	return bytes
+}
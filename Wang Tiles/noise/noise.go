@@ -0,0 +1,101 @@
+//Package noise generates seeded gradient and cellular noise, and fractal
+//sums of it, to build heights and textures procedurally. The same seed
+//always gives the same noise, and every source is defined over the whole
+//plane so terrains can be extended in any direction.
+package noise
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+
+	"github.com/StevenTarazona/glcore/gfx"
+)
+
+//Source2 is noise over the plane. Every source of the package returns
+//values in [-1, 1].
+type Source2 interface {
+	Noise2(x, y float32) float32
+}
+
+//Source3 is noise over space, with values in [-1, 1].
+type Source3 interface {
+	Noise3(x, y, z float32) float32
+}
+
+//Heights samples scaleX x scaleY units of source, starting at offsetX,
+//offsetY, and maps it to [0, magnitude]. The result is the height function
+//of ge.NewTerrainFunc, called with texture coordinates from 0 to 1; use
+//scales in the proportion of the terrain so the noise isn't stretched, and
+//move the offset to continue the noise on a neighbouring terrain.
+func Heights(source Source2, offsetX, offsetY, scaleX, scaleY, magnitude float32) func(u, v float32) float32 {
+	return func(u, v float32) float32 {
+		return magnitude * toUnit(source.Noise2(offsetX+u*scaleX, offsetY+v*scaleY))
+	}
+}
+
+//Image bakes source into a width x height grayscale image, black for -1
+//and white for 1. The image starts at the origin and covers scale units of
+//the source across its width, pixels are square.
+func Image(source Source2, width, height int, scale float32) *image.Gray16 {
+	img := image.NewGray16(image.Rect(0, 0, width, height))
+	step := scale / float32(width)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := toUnit(source.Noise2(float32(x)*step, float32(y)*step))
+			img.SetGray16(x, y, color.Gray16{Y: uint16(value*65535 + .5)})
+		}
+	}
+	return img
+}
+
+//Texture bakes source with Image and uploads it as a data texture, so
+//shaders read the noise values without sRGB conversion, as height maps need.
+//wrapS and wrapT are the wrap modes across and down the image.
+func Texture(source Source2, width, height int, scale float32, wrapS, wrapT int32) (*gfx.Texture, error) {
+	return gfx.NewDataTexture(Image(source, width, height, scale), wrapS, wrapT)
+}
+
+//toUnit maps a noise value from [-1, 1] to [0, 1].
+func toUnit(value float32) float32 {
+	return clamp((value+1)/2, 0, 1)
+}
+
+func clamp(value, min, max float32) float32 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+//fastFloor is math.Floor for the coordinates of the lattice.
+func fastFloor(x float32) int {
+	i := int(x)
+	if float32(i) > x {
+		return i - 1
+	}
+	return i
+}
+
+//permutation is a seeded shuffle of 0 to 255, repeated so lookups can add
+//two indices without wrapping.
+type permutation [512]uint8
+
+func newPermutation(seed int64) *permutation {
+	var p permutation
+	for i, value := range rand.New(rand.NewSource(seed)).Perm(256) {
+		p[i], p[i+256] = uint8(value), uint8(value)
+	}
+	return &p
+}
+
+func (p *permutation) hash2(x, y int) int {
+	return int(p[int(p[x&255])+y&255])
+}
+
+func (p *permutation) hash3(x, y, z int) int {
+	return int(p[int(p[int(p[x&255])+y&255])+z&255])
+}
@@ -0,0 +1,95 @@
+package noise
+
+//Simplex is Ken Perlin's simplex noise, after Stefan Gustavson's
+//implementation. It has fewer directional artifacts than Perlin noise and
+//is cheaper in 3D.
+type Simplex struct {
+	perm *permutation
+}
+
+//NewSimplex returns simplex noise shuffled by seed.
+func NewSimplex(seed int64) *Simplex {
+	return &Simplex{perm: newPermutation(seed)}
+}
+
+const (
+	skew2   = 0.36602540378 // (sqrt(3) - 1) / 2
+	unskew2 = 0.21132486540 // (3 - sqrt(3)) / 6
+	skew3   = 1. / 3
+	unskew3 = 1. / 6
+)
+
+//Noise2 returns the noise at x, y.
+func (s *Simplex) Noise2(x, y float32) float32 {
+	// cell of the skewed grid and the first corner of its triangle
+	skew := (x + y) * skew2
+	i, j := fastFloor(x+skew), fastFloor(y+skew)
+	unskew := float32(i+j) * unskew2
+	x0, y0 := x-(float32(i)-unskew), y-(float32(j)-unskew)
+
+	// the second corner is along x or y depending on the half of the cell
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+	x1, y1 := x0-float32(i1)+unskew2, y0-float32(j1)+unskew2
+	x2, y2 := x0-1+2*unskew2, y0-1+2*unskew2
+
+	n := simplexCorner2(s.perm.hash2(i, j), x0, y0) +
+		simplexCorner2(s.perm.hash2(i+i1, j+j1), x1, y1) +
+		simplexCorner2(s.perm.hash2(i+1, j+1), x2, y2)
+	return clamp(70*n, -1, 1)
+}
+
+//Noise3 returns the noise at x, y, z.
+func (s *Simplex) Noise3(x, y, z float32) float32 {
+	skew := (x + y + z) * skew3
+	i, j, k := fastFloor(x+skew), fastFloor(y+skew), fastFloor(z+skew)
+	unskew := float32(i+j+k) * unskew3
+	x0, y0, z0 := x-(float32(i)-unskew), y-(float32(j)-unskew), z-(float32(k)-unskew)
+
+	// the tetrahedron of the cell is picked by the order of the coordinates
+	var i1, j1, k1, i2, j2, k2 int
+	switch {
+	case x0 >= y0 && y0 >= z0:
+		i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+	case x0 >= y0 && x0 >= z0:
+		i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+	case x0 >= y0:
+		i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+	case y0 < z0:
+		i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+	case x0 < z0:
+		i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+	default:
+		i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+	}
+	x1, y1, z1 := x0-float32(i1)+unskew3, y0-float32(j1)+unskew3, z0-float32(k1)+unskew3
+	x2, y2, z2 := x0-float32(i2)+2*unskew3, y0-float32(j2)+2*unskew3, z0-float32(k2)+2*unskew3
+	x3, y3, z3 := x0-1+3*unskew3, y0-1+3*unskew3, z0-1+3*unskew3
+
+	n := simplexCorner3(s.perm.hash3(i, j, k), x0, y0, z0) +
+		simplexCorner3(s.perm.hash3(i+i1, j+j1, k+k1), x1, y1, z1) +
+		simplexCorner3(s.perm.hash3(i+i2, j+j2, k+k2), x2, y2, z2) +
+		simplexCorner3(s.perm.hash3(i+1, j+1, k+1), x3, y3, z3)
+	return clamp(32*n, -1, 1)
+}
+
+//simplexCorner2 is the contribution of a corner at distance x, y.
+func simplexCorner2(hash int, x, y float32) float32 {
+	t := .5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * grad3(hash, x, y, 0)
+}
+
+func simplexCorner3(hash int, x, y, z float32) float32 {
+	t := .6 - x*x - y*y - z*z
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * grad3(hash, x, y, z)
+}
@@ -0,0 +1,83 @@
+package noise
+
+import (
+	"math"
+)
+
+//Worley is cellular noise: one feature point is scattered in every lattice
+//cell and the noise grows with the distance to the nearest one.
+type Worley struct {
+	perm *permutation
+	// Jitter is how far from the cell center points can be placed, 1 fills
+	// the whole cell and 0 gives a regular grid
+	Jitter float32
+}
+
+//NewWorley returns Worley noise with the points scattered by seed.
+func NewWorley(seed int64) *Worley {
+	return &Worley{perm: newPermutation(seed), Jitter: 1}
+}
+
+//Noise2 maps the distance to the nearest point, clamped to one cell, to
+//[-1, 1]. It is -1 on the points.
+func (w *Worley) Noise2(x, y float32) float32 {
+	f1, _ := w.Distances2(x, y)
+	return 2*clamp(f1, 0, 1) - 1
+}
+
+//Noise3 is Noise2 in space.
+func (w *Worley) Noise3(x, y, z float32) float32 {
+	f1, _ := w.Distances3(x, y, z)
+	return 2*clamp(f1, 0, 1) - 1
+}
+
+//Distances2 returns the distances to the nearest and second nearest
+//points. f2 - f1 is 0 on the borders between cells, to draw cracks or
+//stones.
+func (w *Worley) Distances2(x, y float32) (f1, f2 float32) {
+	f1, f2 = math.MaxFloat32, math.MaxFloat32
+	x0, y0 := fastFloor(x), fastFloor(y)
+	for j := y0 - 1; j <= y0+1; j++ {
+		for i := x0 - 1; i <= x0+1; i++ {
+			hash := w.perm.hash2(i, j)
+			px := float32(i) + w.offset(hash)
+			py := float32(j) + w.offset(int(w.perm[hash+1]))
+			f1, f2 = nearest(f1, f2, (px-x)*(px-x)+(py-y)*(py-y))
+		}
+	}
+	return float32(math.Sqrt(float64(f1))), float32(math.Sqrt(float64(f2)))
+}
+
+//Distances3 is Distances2 in space.
+func (w *Worley) Distances3(x, y, z float32) (f1, f2 float32) {
+	f1, f2 = math.MaxFloat32, math.MaxFloat32
+	x0, y0, z0 := fastFloor(x), fastFloor(y), fastFloor(z)
+	for k := z0 - 1; k <= z0+1; k++ {
+		for j := y0 - 1; j <= y0+1; j++ {
+			for i := x0 - 1; i <= x0+1; i++ {
+				hash := w.perm.hash3(i, j, k)
+				px := float32(i) + w.offset(hash)
+				py := float32(j) + w.offset(int(w.perm[hash+1]))
+				pz := float32(k) + w.offset(int(w.perm[hash+2]))
+				f1, f2 = nearest(f1, f2, (px-x)*(px-x)+(py-y)*(py-y)+(pz-z)*(pz-z))
+			}
+		}
+	}
+	return float32(math.Sqrt(float64(f1))), float32(math.Sqrt(float64(f2)))
+}
+
+//offset places a point coordinate inside its cell from a hash.
+func (w *Worley) offset(hash int) float32 {
+	return .5 + w.Jitter*(float32(hash)/255-.5)
+}
+
+//nearest keeps the two smallest squared distances.
+func nearest(f1, f2, distance float32) (float32, float32) {
+	if distance < f1 {
+		return distance, f1
+	}
+	if distance < f2 {
+		return f1, distance
+	}
+	return f1, f2
+}
@@ -0,0 +1,12 @@
+#version 410 core
+layout (location = 0) in vec3 aPos;
+layout (location = 2) in vec2 texCoord;
+
+out vec2 TexCoordTC;
+
+void main()
+{
+    // the tessellation stages transform the displaced vertices
+    gl_Position = vec4(aPos, 1.0);
+    TexCoordTC = texCoord;
+}